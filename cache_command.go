@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Подкоманды команды cache
+const cacheUsage = "usage: cache stats | cache ls [prefix] | cache purge [prefix] | cache warm <resource>"
+
+func commandCache(ctx context.Context, cfg *Config, parameters []string) error {
+	if len(parameters) == 0 {
+		return errors.New(cacheUsage)
+	}
+
+	// Префикс указывается относительно адреса API, например pokemon/
+	prefix := ""
+	if len(parameters) > 1 {
+		prefix = parameters[1]
+	}
+
+	switch parameters[0] {
+	case "stats":
+		return cacheStats(cfg)
+	case "ls":
+		return cacheList(cfg, prefix)
+	case "purge":
+		removed := cfg.pokeCache.Purge(cfg.pokeapiClient.BaseURL() + prefix)
+		return render(cfg, messageView{fmt.Sprintf("Removed %d cache entries", removed)})
+	case "warm":
+		if prefix == "" {
+			return errors.New("usage: cache warm location-area | location-area/<name> | pokemon/<name>")
+		}
+		return cacheWarm(ctx, cfg, prefix)
+	default:
+		return errors.New(cacheUsage)
+	}
+}
+
+// Счетчики кэша для cache stats
+type cacheStatsView struct {
+	Entries     int `json:"entries"`
+	Bytes       int `json:"bytes"`
+	Hits        int `json:"hits"`
+	Misses      int `json:"misses"`
+	Evictions   int `json:"evictions"`
+	Expirations int `json:"expirations"`
+}
+
+func (v cacheStatsView) writeText(w io.Writer) {
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "Entries:     %d\n", v.Entries)
+	fmt.Fprintf(w, "Size:        %s\n", formatBytes(v.Bytes))
+	fmt.Fprintf(w, "Hits:        %d\n", v.Hits)
+	fmt.Fprintf(w, "Misses:      %d\n", v.Misses)
+	fmt.Fprintf(w, "Evictions:   %d\n", v.Evictions)
+	fmt.Fprintf(w, "Expirations: %d\n", v.Expirations)
+	fmt.Fprintln(w)
+}
+
+// Элементы кэша для cache ls
+type cacheEntriesView struct {
+	Entries []cacheEntryView `json:"entries"`
+}
+
+type cacheEntryView struct {
+	Resource  string    `json:"resource"`
+	Bytes     int       `json:"bytes"`
+	Fresh     bool      `json:"fresh"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func (v cacheEntriesView) writeText(w io.Writer) {
+	if len(v.Entries) == 0 {
+		fmt.Fprintln(w, "No cache entries found.")
+		return
+	}
+
+	now := time.Now()
+	fmt.Fprintln(w)
+	for _, entry := range v.Entries {
+		state := "fresh"
+		if !entry.Fresh {
+			state = "stale"
+		}
+		age := now.Sub(entry.CreatedAt).Round(time.Second)
+		fmt.Fprintf(w, " - %s (%s, %s, %s old)\n", entry.Resource, formatBytes(entry.Bytes), state, age)
+	}
+	fmt.Fprintln(w)
+}
+
+// cacheStats выводит счетчики кэша
+func cacheStats(cfg *Config) error {
+	stats := cfg.pokeCache.Stats()
+
+	return render(cfg, cacheStatsView{
+		Entries:     stats.Entries,
+		Bytes:       stats.Bytes,
+		Hits:        stats.Hits,
+		Misses:      stats.Misses,
+		Evictions:   stats.Evictions,
+		Expirations: stats.Expirations,
+	})
+}
+
+// cacheList выводит элементы кэша с их возрастом
+func cacheList(cfg *Config, prefix string) error {
+	baseURL := cfg.pokeapiClient.BaseURL()
+
+	v := cacheEntriesView{Entries: []cacheEntryView{}}
+	for _, entry := range cfg.pokeCache.Entries(baseURL + prefix) {
+		v.Entries = append(v.Entries, cacheEntryView{
+			Resource:  strings.TrimPrefix(entry.Key, baseURL),
+			Bytes:     entry.Size,
+			Fresh:     entry.Fresh,
+			CreatedAt: entry.CreatedAt,
+			ExpiresAt: entry.ExpiresAt,
+		})
+	}
+
+	return render(cfg, v)
+}
+
+// cacheWarm заранее загружает ресурс в кэш
+func cacheWarm(ctx context.Context, cfg *Config, resource string) error {
+	kind, name, _ := strings.Cut(strings.Trim(resource, "/"), "/")
+
+	switch {
+	case kind == "location-area" && name == "":
+		// Проходим все страницы списка локаций
+		pages := 0
+		pageURL := ""
+		for {
+			locations, err := cfg.pokeapiClient.ListLocationAreas(ctx, pageURL)
+			if err != nil {
+				return err
+			}
+			pages++
+			if locations.Next == "" {
+				break
+			}
+			pageURL = locations.Next
+		}
+		return render(cfg, messageView{fmt.Sprintf("Cached %d pages of location areas", pages)})
+	case kind == "location-area":
+		if _, err := cfg.pokeapiClient.GetLocationArea(ctx, name); err != nil {
+			return err
+		}
+		return render(cfg, messageView{fmt.Sprintf("Cached location area %s", name)})
+	case kind == "pokemon" && name != "":
+		if _, err := cfg.pokeapiClient.GetPokemon(ctx, name); err != nil {
+			return err
+		}
+		return render(cfg, messageView{fmt.Sprintf("Cached pokemon %s", name)})
+	default:
+		return fmt.Errorf("unknown resource %q", resource)
+	}
+}
+
+// formatBytes выводит размер в удобных единицах
+func formatBytes(n int) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MiB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KiB", float64(n)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", n)
+	}
+}
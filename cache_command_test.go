@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCacheCommand(t *testing.T) {
+	// Две страницы локаций и один покемон
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.RequestURI() {
+		case "/location-area/?offset=0&limit=20":
+			fmt.Fprintf(w, `{"next": "%s/location-area/?offset=20&limit=20", "previous": null,
+				"results": [{"name": "canalave-city-area"}]}`, server.URL)
+		case "/location-area/?offset=20&limit=20":
+			fmt.Fprint(w, `{"next": "", "previous": null, "results": [{"name": "eterna-city-area"}]}`)
+		case "/pokemon/pikachu/":
+			fmt.Fprint(w, `{"name": "pikachu", "base_experience": 112}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	cfg, out, errOut := newTestConfig(t, server, "")
+	dispatch := func(input string) error {
+		out.Reset()
+		errOut.Reset()
+		return cfg.registry.dispatch(context.Background(), cfg, input)
+	}
+
+	// Ошибки использования
+	usage := []struct {
+		input    string
+		expected string
+	}{
+		{input: "cache", expected: "usage: cache stats"},
+		{input: "cache flush", expected: "usage: cache stats"},
+		{input: "cache warm", expected: "usage: cache warm location-area"},
+		{input: "cache warm berry/cheri", expected: `unknown resource "berry/cheri"`},
+	}
+	for _, c := range usage {
+		if err := dispatch(c.input); err == nil {
+			t.Errorf("%q: expected error", c.input)
+		}
+		if !strings.Contains(errOut.String(), c.expected) {
+			t.Errorf("%q: expected %q, got %q", c.input, c.expected, errOut.String())
+		}
+	}
+
+	// warm проходит все страницы списка локаций
+	warm := []struct {
+		input    string
+		expected string
+	}{
+		{input: "cache warm location-area", expected: "Cached 2 pages of location areas"},
+		{input: "cache warm pokemon/pikachu", expected: "Cached pokemon pikachu"},
+	}
+	for _, c := range warm {
+		if err := dispatch(c.input); err != nil {
+			t.Fatalf("%q: unexpected error: %v, %s", c.input, err, errOut)
+		}
+		if !strings.Contains(out.String(), c.expected) {
+			t.Errorf("%q: expected %q, got %q", c.input, c.expected, out.String())
+		}
+	}
+
+	// ls показывает ресурсы относительно адреса API
+	list := []struct {
+		input    string
+		expected []string
+	}{
+		{
+			input:    "cache ls -o json",
+			expected: []string{"location-area/?offset=0&limit=20", "location-area/?offset=20&limit=20", "pokemon/pikachu/"},
+		},
+		{
+			input:    "cache ls pokemon/ -o json",
+			expected: []string{"pokemon/pikachu/"},
+		},
+		{
+			input:    "cache ls berry/ -o json",
+			expected: []string{},
+		},
+	}
+	for _, c := range list {
+		if err := dispatch(c.input); err != nil {
+			t.Fatalf("%q: unexpected error: %v, %s", c.input, err, errOut)
+		}
+		var v cacheEntriesView
+		if err := json.Unmarshal(out.Bytes(), &v); err != nil {
+			t.Fatalf("%q: invalid JSON %q: %v", c.input, out.String(), err)
+		}
+		actual := []string{}
+		for _, entry := range v.Entries {
+			actual = append(actual, entry.Resource)
+			if !entry.Fresh || entry.Bytes == 0 {
+				t.Errorf("%q: expected fresh non-empty entry, got %+v", c.input, entry)
+			}
+		}
+		if strings.Join(actual, ",") != strings.Join(c.expected, ",") {
+			t.Errorf("%q: expected %v, got %v", c.input, c.expected, actual)
+		}
+	}
+
+	// Каждый warm - один промах, ls счетчики не трогает
+	stats := func() cacheStatsView {
+		t.Helper()
+		if err := dispatch("cache stats -o json"); err != nil {
+			t.Fatalf("stats: unexpected error: %v, %s", err, errOut)
+		}
+		var v cacheStatsView
+		if err := json.Unmarshal(out.Bytes(), &v); err != nil {
+			t.Fatalf("stats: invalid JSON %q: %v", out.String(), err)
+		}
+		return v
+	}
+	if v := stats(); v.Entries != 3 || v.Bytes == 0 || v.Hits != 0 || v.Misses != 3 {
+		t.Errorf("unexpected stats: %+v", v)
+	}
+
+	// purge удаляет только ресурсы с префиксом
+	if err := dispatch("cache purge location-area/"); err != nil {
+		t.Fatalf("purge: unexpected error: %v, %s", err, errOut)
+	}
+	if !strings.Contains(out.String(), "Removed 2 cache entries") {
+		t.Errorf("purge: expected 2 removed entries, got %q", out.String())
+	}
+	if v := stats(); v.Entries != 1 {
+		t.Errorf("expected 1 entry after purge, got %+v", v)
+	}
+}
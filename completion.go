@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Сколько ждать списков имен из API при дополнении
+const completionTimeout = 3 * time.Second
+
+// complete подсказывает варианты для слова под курсором.
+// line - текст до курсора, start - начало дополняемого слова
+func complete(ctx context.Context, cfg *Config, line string) (int, []string) {
+	start := strings.LastIndex(line, " ") + 1
+	word := strings.ToLower(line[start:])
+	previous := strings.Fields(line[:start])
+
+	// Первое слово - команда
+	if len(previous) == 0 {
+		return start, matchPrefix(word, commandNames(cfg))
+	}
+
+	// Дополняем только первый аргумент команды
+	cmd, exists := cfg.registry.lookup(strings.ToLower(previous[0]))
+	if !exists || len(previous) != 1 {
+		return start, nil
+	}
+
+	switch cmd.name {
+	case "help":
+		return start, matchPrefix(word, commandNames(cfg))
+	case "explore":
+		return start, matchPrefix(word, areaNames(ctx, cfg))
+	case "catch":
+		return start, matchPrefix(word, append(caughtNames(cfg), pokemonNames(ctx, cfg)...))
+	case "inspect":
+		// Сначала пойманные покемоны, полный список - если среди них ничего нет
+		if candidates := matchPrefix(word, caughtNames(cfg)); len(candidates) > 0 {
+			return start, candidates
+		}
+		return start, matchPrefix(word, pokemonNames(ctx, cfg))
+	}
+	return start, nil
+}
+
+// commandNames возвращает имена команд, их псевдонимы
+// и пользовательские псевдонимы и макросы
+func commandNames(cfg *Config) []string {
+	r := cfg.registry
+	names := make([]string, 0, len(r.commands)+len(r.aliases))
+	for name := range r.commands {
+		names = append(names, name)
+	}
+	for alias := range r.aliases {
+		names = append(names, alias)
+	}
+	return append(names, userCommandNames(cfg)...)
+}
+
+// areaNames возвращает локации, увиденные через map, и полный список из API.
+// Если список получить не удалось, дополняем только увиденными
+func areaNames(ctx context.Context, cfg *Config) []string {
+	names := make([]string, 0, len(cfg.seenAreas))
+	for name := range cfg.seenAreas {
+		names = append(names, name)
+	}
+	return append(names, areaIndex(ctx, cfg)...)
+}
+
+// areaIndex возвращает полный список локаций из API, пустой при ошибке
+func areaIndex(ctx context.Context, cfg *Config) []string {
+	names, err := cfg.pokeapiClient.ListLocationAreaNames(ctx)
+	if err != nil {
+		return nil
+	}
+	return names
+}
+
+// caughtNames возвращает имена пойманных покемонов
+func caughtNames(cfg *Config) []string {
+	names := make([]string, 0, len(cfg.Pokedex))
+	for name := range cfg.Pokedex {
+		names = append(names, name)
+	}
+	return names
+}
+
+// pokemonNames возвращает национальный список покемонов, пустой при ошибке
+func pokemonNames(ctx context.Context, cfg *Config) []string {
+	names, err := cfg.pokeapiClient.ListPokemonNames(ctx)
+	if err != nil {
+		return nil
+	}
+	return names
+}
+
+// matchPrefix оставляет слова с заданным началом, без повторов и по алфавиту
+func matchPrefix(prefix string, words []string) []string {
+	seen := make(map[string]bool)
+	matches := []string{}
+	for _, word := range words {
+		if strings.HasPrefix(word, prefix) && !seen[word] {
+			seen[word] = true
+			matches = append(matches, word)
+		}
+	}
+	sort.Strings(matches)
+	return matches
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestComplete(t *testing.T) {
+	server := newTestServer(t)
+	cfg, _, _ := newTestConfig(t, server, "")
+	cfg.Pokedex["pidgeotto"] = CaughtPokemon{}
+
+	cases := []struct {
+		line          string
+		expectedStart int
+		expected      []string
+	}{
+		{line: "ex", expectedStart: 0, expected: []string{"exit", "explore"}},
+		{line: "qu", expectedStart: 0, expected: []string{"quit"}},
+		{line: "help ca", expectedStart: 5, expected: []string{"cache", "catch"}},
+		{line: "explore et", expectedStart: 8, expected: []string{"eterna-city-area"}},
+		{line: "catch pi", expectedStart: 6, expected: []string{"pidgeotto", "pidgey", "pikachu"}},
+		{line: "catch MR", expectedStart: 6, expected: []string{"mr-mime"}},
+		{line: "inspect pi", expectedStart: 8, expected: []string{"pidgeotto"}},
+		{line: "inspect mr", expectedStart: 8, expected: []string{"mr-mime"}},
+		{line: "catch pikachu pi", expectedStart: 14, expected: nil},
+		{line: "nope pi", expectedStart: 5, expected: nil},
+	}
+
+	for _, c := range cases {
+		start, actual := complete(context.Background(), cfg, c.line)
+		if start != c.expectedStart {
+			t.Errorf("%q: expected start %d, got %d", c.line, c.expectedStart, start)
+		}
+		if strings.Join(actual, ",") != strings.Join(c.expected, ",") {
+			t.Errorf("%q: expected %v, got %v", c.line, c.expected, actual)
+		}
+	}
+}
+
+func TestCompleteSeenAreas(t *testing.T) {
+	// Без API дополняем локациями, которые уже показывал map
+	cfg, _, _ := newTestConfig(t, nil, "")
+	cfg.seenAreas = map[string]bool{"canalave-city-area": true}
+
+	_, actual := complete(context.Background(), cfg, "explore can")
+	if len(actual) != 1 || actual[0] != "canalave-city-area" {
+		t.Errorf("expected seen location area, got %v", actual)
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/CodeHunt7/go-pokedex/internal/pokeapi"
+)
+
+// errorMessage переводит ошибку команды в сообщение для пользователя.
+// Ошибки API описываются по их виду, без ссылок и тел ответов
+func errorMessage(err error) string {
+	var (
+		notFound    *pokeapi.NotFoundError
+		rateLimited *pokeapi.RateLimitedError
+		upstream    *pokeapi.UpstreamError
+		timeout     net.Error
+	)
+
+	switch {
+	case errors.Is(err, pokeapi.ErrOffline):
+		return "not available offline, the snapshot does not have it"
+	case errors.As(err, &notFound):
+		// Вид ресурса берем из ссылки: location-area -> location area
+		kind, name := notFound.Resource()
+		if name == "" {
+			return "not found in PokeAPI"
+		}
+		return fmt.Sprintf("unknown %s %q", strings.ReplaceAll(kind, "-", " "), name)
+	case errors.As(err, &rateLimited):
+		if rateLimited.RetryAfter > 0 {
+			return fmt.Sprintf("PokeAPI is limiting requests, try again in %s", rateLimited.RetryAfter)
+		}
+		return "PokeAPI is limiting requests, try again later"
+	case errors.As(err, &upstream):
+		return fmt.Sprintf("PokeAPI is unavailable right now (status %d), try again later", upstream.StatusCode)
+	case errors.Is(err, pokeapi.ErrDecode):
+		return "PokeAPI sent a response the Pokedex could not read"
+	case errors.Is(err, pokeapi.ErrNetwork) && errors.As(err, &timeout) && timeout.Timeout():
+		return "PokeAPI did not respond in time, try again later"
+	case errors.Is(err, pokeapi.ErrNetwork):
+		return "could not reach PokeAPI, check your internet connection"
+	}
+	return err.Error()
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestErrorMessages(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/location-area/":
+			w.Header().Set("Retry-After", "5")
+			w.WriteHeader(http.StatusTooManyRequests)
+		case "/pokemon/":
+			fmt.Fprint(w, `{"results": [{"name": "pikachu"}, {"name": "missingno"}, {"name": "ghost"}]}`)
+		case "/pokemon/pikachu/":
+			w.WriteHeader(http.StatusServiceUnavailable)
+			fmt.Fprint(w, "<html><body>Service Unavailable</body></html>")
+		case "/pokemon/missingno/":
+			fmt.Fprint(w, "<html>")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	cases := []struct {
+		input    string
+		expected string
+	}{
+		{input: "map", expected: `Error executing "map" command: PokeAPI is limiting requests, try again in 5s`},
+		{input: "catch pikachu", expected: `Error executing "catch" command: PokeAPI is unavailable right now (status 503), try again later`},
+		{input: "catch missingno", expected: `Error executing "catch" command: PokeAPI sent a response the Pokedex could not read`},
+		// 404 описывается видом ресурса из ссылки
+		{input: "catch ghost", expected: `Error executing "catch" command: unknown pokemon "ghost"`},
+		{input: "explore nowhere", expected: `Error executing "explore" command: unknown location area "nowhere"`},
+	}
+
+	for _, c := range cases {
+		cfg, _, errOut := newTestConfig(t, server, "")
+
+		if err := cfg.registry.dispatch(context.Background(), cfg, c.input); err == nil {
+			t.Errorf("%q: expected error", c.input)
+		}
+		if actual := strings.TrimSpace(errOut.String()); actual != c.expected {
+			t.Errorf("%q: expected %q, got %q", c.input, c.expected, actual)
+		}
+	}
+
+	// Недоступный API
+	cfg, _, errOut := newTestConfig(t, server, "")
+	server.Close()
+	cfg.registry.dispatch(context.Background(), cfg, "map")
+	if !strings.Contains(errOut.String(), "could not reach PokeAPI") {
+		t.Errorf("expected network error message, got %q", errOut.String())
+	}
+}
@@ -0,0 +1,90 @@
+package lineedit
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Completer возвращает варианты дополнения для слова под курсором.
+// line - текст до курсора, start - байтовая позиция начала дополняемого слова
+type Completer func(line string) (start int, candidates []string)
+
+// Сколько вариантов показывать на второй Tab
+const maxListed = 100
+
+// SetCompleter включает дополнение по Tab
+func (e *Editor) SetCompleter(c Completer) {
+	e.completer = c
+}
+
+// complete дополняет слово под курсором общим началом вариантов.
+// Если дополнить нечего, повторный Tab (list) выводит все варианты
+func (e *Editor) complete(l *line, list bool) {
+	if e.completer == nil {
+		return
+	}
+
+	head := string(l.buf[:l.pos])
+	start, candidates := e.completer(head)
+	if len(candidates) == 0 || start < 0 || start > len(head) {
+		fmt.Fprint(e.out, "\a")
+		return
+	}
+
+	word := head[start:]
+	prefix := commonPrefix(candidates)
+	// Единственный вариант дописываем целиком, вместе с пробелом после слова
+	if len(candidates) == 1 && (l.pos == len(l.buf) || l.buf[l.pos] != ' ') {
+		prefix += " "
+	}
+
+	// Варианты могут отличаться от набранного регистром: PIK -> pikachu
+	if prefix != word && strings.HasPrefix(strings.ToLower(prefix), strings.ToLower(word)) {
+		wordStart := utf8.RuneCountInString(head[:start])
+		l.buf = append(l.buf[:wordStart], append([]rune(prefix), l.buf[l.pos:]...)...)
+		l.pos = wordStart + utf8.RuneCountInString(prefix)
+		return
+	}
+
+	if !list {
+		fmt.Fprint(e.out, "\a")
+		return
+	}
+	e.listCandidates(candidates)
+}
+
+// listCandidates выводит варианты в колонки под строкой ввода
+func (e *Editor) listCandidates(candidates []string) {
+	shown := candidates[:min(len(candidates), maxListed)]
+
+	width := 0
+	for _, candidate := range shown {
+		width = max(width, utf8.RuneCountInString(candidate))
+	}
+	width += 2
+	columns := max(80/width, 1)
+
+	fmt.Fprint(e.out, "\r\n")
+	for i, candidate := range shown {
+		fmt.Fprintf(e.out, "%-*s", width, candidate)
+		if (i+1)%columns == 0 || i == len(shown)-1 {
+			fmt.Fprint(e.out, "\r\n")
+		}
+	}
+	if hidden := len(candidates) - len(shown); hidden > 0 {
+		fmt.Fprintf(e.out, "... and %d more\r\n", hidden)
+	}
+}
+
+// commonPrefix возвращает общее начало всех строк
+func commonPrefix(words []string) string {
+	prefix := words[0]
+	for _, word := range words[1:] {
+		for !strings.HasPrefix(word, prefix) {
+			_, size := utf8.DecodeLastRuneInString(prefix)
+			prefix = prefix[:len(prefix)-size]
+		}
+	}
+	return prefix
+}
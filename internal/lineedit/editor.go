@@ -0,0 +1,358 @@
+package lineedit
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// ErrInterrupted возвращает ReadLine, если пользователь нажал Ctrl-C
+var ErrInterrupted = errors.New("interrupted")
+
+// Управляющие символы, которые понимает редактор
+const (
+	ctrlA     = 1
+	ctrlB     = 2
+	ctrlC     = 3
+	ctrlD     = 4
+	ctrlE     = 5
+	ctrlF     = 6
+	ctrlG     = 7
+	ctrlH     = 8
+	ctrlK     = 11
+	ctrlL     = 12
+	ctrlN     = 14
+	ctrlP     = 16
+	ctrlR     = 18
+	ctrlU     = 21
+	ctrlW     = 23
+	esc       = 27
+	backspace = 127
+)
+
+// Специальные клавиши, которые приходят escape-последовательностями
+const (
+	keyUnknown rune = -(iota + 1)
+	keyUp
+	keyDown
+	keyRight
+	keyLeft
+	keyHome
+	keyEnd
+	keyDelete
+	keyEscape
+)
+
+// Editor читает строки с редактированием, историей и обратным поиском
+type Editor struct {
+	in      *bufio.Reader
+	out     *bufio.Writer
+	fd      int // -1 - терминал не переключается, например в тестах
+	history *History
+
+	completer Completer // nil - Tab ничего не делает
+}
+
+// New создает редактор поверх любого ввода, не трогая режим терминала
+func New(in io.Reader, out io.Writer, history *History) *Editor {
+	if history == nil {
+		history = NewHistory(DefaultHistorySize)
+	}
+	return &Editor{
+		in:      bufio.NewReader(in),
+		out:     bufio.NewWriter(out),
+		fd:      -1,
+		history: history,
+	}
+}
+
+// NewTerminal создает редактор для терминала.
+// На время чтения строки терминал переводится в raw-режим
+func NewTerminal(in *os.File, out io.Writer, history *History) *Editor {
+	e := New(in, out, history)
+	e.fd = int(in.Fd())
+	return e
+}
+
+// History возвращает историю редактора
+func (e *Editor) History() *History {
+	return e.history
+}
+
+// Редактируемая строка
+type line struct {
+	prompt  string
+	buf     []rune
+	pos     int    // позиция курсора в buf
+	histPos int    // строка истории на экране, history.Len() - новая строка
+	saved   []rune // новая строка, пока листаем историю
+}
+
+// ReadLine выводит приглашение и читает строку.
+// Введенная строка добавляется в историю. Ctrl-C возвращает ErrInterrupted,
+// Ctrl-D на пустой строке - io.EOF
+func (e *Editor) ReadLine(prompt string) (string, error) {
+	if e.fd >= 0 {
+		restore, err := makeRaw(e.fd)
+		if err != nil {
+			return "", err
+		}
+		defer restore()
+	}
+	defer e.out.Flush()
+
+	l := &line{prompt: prompt, histPos: e.history.Len()}
+	e.refresh(l)
+	lastTab := false
+	for {
+		key, err := e.readKey()
+		if err != nil {
+			// Ввод оборвался посреди строки - отдаем то, что успели набрать
+			if errors.Is(err, io.EOF) && len(l.buf) > 0 {
+				return e.accept(l), nil
+			}
+			return "", err
+		}
+
+		if key == ctrlR {
+			if key, err = e.search(l); err != nil {
+				return "", err
+			}
+		}
+
+		tab := key == '\t'
+		switch key {
+		case '\t':
+			e.complete(l, lastTab)
+		case '\r', '\n':
+			return e.accept(l), nil
+		case ctrlC:
+			fmt.Fprint(e.out, "^C\r\n")
+			return "", ErrInterrupted
+		case ctrlD:
+			if len(l.buf) == 0 {
+				return "", io.EOF
+			}
+			l.deleteAt(l.pos)
+		case backspace, ctrlH:
+			if l.pos > 0 {
+				l.pos--
+				l.deleteAt(l.pos)
+			}
+		case keyDelete:
+			l.deleteAt(l.pos)
+		case ctrlA, keyHome:
+			l.pos = 0
+		case ctrlE, keyEnd:
+			l.pos = len(l.buf)
+		case ctrlB, keyLeft:
+			l.pos = max(l.pos-1, 0)
+		case ctrlF, keyRight:
+			l.pos = min(l.pos+1, len(l.buf))
+		case ctrlK:
+			l.buf = l.buf[:l.pos]
+		case ctrlU:
+			l.buf = l.buf[l.pos:]
+			l.pos = 0
+		case ctrlW:
+			l.deleteWord()
+		case ctrlL:
+			fmt.Fprint(e.out, "\x1b[H\x1b[2J")
+		case ctrlP, keyUp:
+			e.historyMove(l, -1)
+		case ctrlN, keyDown:
+			e.historyMove(l, 1)
+		default:
+			if key >= ' ' && unicode.IsPrint(key) {
+				l.insert(key)
+			}
+		}
+		lastTab = tab
+		e.refresh(l)
+	}
+}
+
+// accept завершает строку и добавляет ее в историю
+func (e *Editor) accept(l *line) string {
+	fmt.Fprint(e.out, "\r\n")
+	text := string(l.buf)
+	e.history.Add(text)
+	return text
+}
+
+// refresh перерисовывает приглашение и строку и ставит курсор на место
+func (e *Editor) refresh(l *line) {
+	fmt.Fprintf(e.out, "\r%s%s\x1b[K", l.prompt, string(l.buf))
+	if n := len(l.buf) - l.pos; n > 0 {
+		fmt.Fprintf(e.out, "\x1b[%dD", n)
+	}
+}
+
+// historyMove листает историю: -1 - строка старше, 1 - новее
+func (e *Editor) historyMove(l *line, delta int) {
+	next := l.histPos + delta
+	if next < 0 || next > e.history.Len() {
+		return
+	}
+
+	// Запоминаем новую строку, чтобы вернуться к ней
+	if l.histPos == e.history.Len() {
+		l.saved = slices.Clone(l.buf)
+	}
+	l.histPos = next
+	if next == e.history.Len() {
+		l.buf = slices.Clone(l.saved)
+	} else {
+		l.buf = []rune(e.history.entries[next])
+	}
+	l.pos = len(l.buf)
+}
+
+// search - обратный поиск по истории (Ctrl-R).
+// Каждый следующий Ctrl-R ищет строку старше, Ctrl-G отменяет поиск.
+// Возвращает клавишу, которой поиск завершился, чтобы ReadLine ее обработал
+func (e *Editor) search(l *line) (rune, error) {
+	original, originalPos := slices.Clone(l.buf), l.pos
+	query := []rune{}
+	match := e.history.Len()
+	found := true
+
+	// find ищет query в истории раньше from и показывает найденную строку
+	find := func(from int) {
+		i := e.history.search(string(query), from)
+		found = i >= 0
+		if !found {
+			return
+		}
+		match = i
+		l.histPos = i
+		l.buf = []rune(e.history.entries[i])
+		l.pos = len([]rune(e.history.entries[i][:strings.Index(e.history.entries[i], string(query))]))
+	}
+
+	for {
+		e.refreshSearch(l, string(query), found)
+		key, err := e.readKey()
+		if err != nil {
+			return 0, err
+		}
+
+		switch {
+		case key == ctrlR:
+			if len(query) > 0 {
+				find(match)
+			}
+		case key == backspace || key == ctrlH:
+			if len(query) > 0 {
+				query = query[:len(query)-1]
+				find(e.history.Len())
+			}
+		case key == ctrlG:
+			l.buf, l.pos = original, originalPos
+			l.histPos = e.history.Len()
+			return 0, nil
+		case key >= ' ' && unicode.IsPrint(key):
+			query = append(query, key)
+			find(min(match+1, e.history.Len()))
+		default:
+			return key, nil
+		}
+	}
+}
+
+// refreshSearch рисует строку обратного поиска
+func (e *Editor) refreshSearch(l *line, query string, found bool) {
+	label := "reverse-i-search"
+	if !found {
+		label = "failed reverse-i-search"
+	}
+	fmt.Fprintf(e.out, "\r(%s)`%s': %s\x1b[K", label, query, string(l.buf))
+}
+
+// readKey читает одну клавишу и разбирает escape-последовательности стрелок и Home/End/Delete
+func (e *Editor) readKey() (rune, error) {
+	e.out.Flush()
+
+	r, _, err := e.in.ReadRune()
+	if err != nil || r != esc {
+		return r, err
+	}
+
+	next, _, err := e.in.ReadRune()
+	if err != nil {
+		return keyEscape, nil
+	}
+	if next != '[' && next != 'O' {
+		e.in.UnreadRune()
+		return keyEscape, nil
+	}
+
+	// Параметры последовательности - цифры и ';', потом завершающий символ
+	var param []rune
+	for {
+		c, _, err := e.in.ReadRune()
+		if err != nil {
+			return keyUnknown, nil
+		}
+		if (c >= '0' && c <= '9') || c == ';' {
+			param = append(param, c)
+			continue
+		}
+
+		switch c {
+		case 'A':
+			return keyUp, nil
+		case 'B':
+			return keyDown, nil
+		case 'C':
+			return keyRight, nil
+		case 'D':
+			return keyLeft, nil
+		case 'H':
+			return keyHome, nil
+		case 'F':
+			return keyEnd, nil
+		case '~':
+			switch string(param) {
+			case "1", "7":
+				return keyHome, nil
+			case "4", "8":
+				return keyEnd, nil
+			case "3":
+				return keyDelete, nil
+			}
+		}
+		return keyUnknown, nil
+	}
+}
+
+// insert вставляет символ под курсор
+func (l *line) insert(r rune) {
+	l.buf = slices.Insert(l.buf, l.pos, r)
+	l.pos++
+}
+
+// deleteAt удаляет символ в позиции i, если он есть
+func (l *line) deleteAt(i int) {
+	if i < len(l.buf) {
+		l.buf = slices.Delete(l.buf, i, i+1)
+	}
+}
+
+// deleteWord удаляет слово перед курсором
+func (l *line) deleteWord() {
+	start := l.pos
+	for start > 0 && l.buf[start-1] == ' ' {
+		start--
+	}
+	for start > 0 && l.buf[start-1] != ' ' {
+		start--
+	}
+	l.buf = slices.Delete(l.buf, start, l.pos)
+	l.pos = start
+}
@@ -0,0 +1,140 @@
+package lineedit
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestReadLine(t *testing.T) {
+	cases := []struct {
+		name     string
+		history  []string
+		input    string
+		expected string
+	}{
+		{name: "plain", input: "map\r", expected: "map"},
+		{name: "newline", input: "help\n", expected: "help"},
+		{name: "backspace", input: "mapx\x7f\r", expected: "map"},
+		{name: "left and insert", input: "ctch\x1b[D\x1b[D\x1b[Da\r", expected: "catch"},
+		{name: "home and end", input: "atch\x1b[Hc\x1b[F pikachu\r", expected: "catch pikachu"},
+		{name: "ctrl-a and ctrl-e", input: "xplore\x01e\x05 area\r", expected: "explore area"},
+		{name: "delete", input: "mapp\x1b[D\x1b[3~\r", expected: "map"},
+		{name: "kill to end", input: "map now\x1b[D\x1b[D\x1b[D\x1b[D\x0b\r", expected: "map"},
+		{name: "kill to start", input: "oops map\x1b[D\x1b[D\x1b[D\x15\r", expected: "map"},
+		{name: "delete word", input: "catch pikachu\x17\r", expected: "catch "},
+		{name: "unicode", input: "catch flabébé\x7f\x7f\r", expected: "catch flabé"},
+		{name: "history up", history: []string{"map", "explore area"}, input: "\x1b[A\r", expected: "explore area"},
+		{name: "history up twice", history: []string{"map", "explore area"}, input: "\x1b[A\x1b[A\r", expected: "map"},
+		{name: "history back down", history: []string{"map"}, input: "cat\x1b[A\x1b[B\r", expected: "cat"},
+		{name: "history stops at oldest", history: []string{"map"}, input: "\x10\x10\x10\r", expected: "map"},
+		{name: "reverse search", history: []string{"catch pikachu", "map", "catch bulbasaur"}, input: "\x12catch\r", expected: "catch bulbasaur"},
+		{name: "reverse search older", history: []string{"catch pikachu", "map", "catch bulbasaur"}, input: "\x12catch\x12\r", expected: "catch pikachu"},
+		{name: "reverse search then edit", history: []string{"catch pikachu"}, input: "\x12pika\x05!\r", expected: "catch pikachu!"},
+		{name: "reverse search cancel", history: []string{"catch pikachu"}, input: "he\x12pika\x07lp\r", expected: "help"},
+		{name: "reverse search no match", history: []string{"map"}, input: "\x12zzz\r", expected: ""},
+		{name: "eof mid line", input: "pokedex", expected: "pokedex"},
+	}
+
+	for _, c := range cases {
+		history := NewHistory(10)
+		for _, entry := range c.history {
+			history.Add(entry)
+		}
+		editor := New(strings.NewReader(c.input), io.Discard, history)
+
+		actual, err := editor.ReadLine("> ")
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", c.name, err)
+			continue
+		}
+		if actual != c.expected {
+			t.Errorf("%s: expected %q, got %q", c.name, c.expected, actual)
+		}
+	}
+}
+
+func TestReadLineControl(t *testing.T) {
+	editor := New(strings.NewReader("map\x03\x04"), io.Discard, nil)
+
+	if _, err := editor.ReadLine("> "); !errors.Is(err, ErrInterrupted) {
+		t.Errorf("expected ErrInterrupted after Ctrl-C, got %v", err)
+	}
+	if _, err := editor.ReadLine("> "); !errors.Is(err, io.EOF) {
+		t.Errorf("expected io.EOF after Ctrl-D, got %v", err)
+	}
+	if editor.History().Len() != 0 {
+		t.Errorf("interrupted line should not be added to history")
+	}
+}
+
+func TestReadLineAddsHistory(t *testing.T) {
+	editor := New(strings.NewReader("map\rmap\r\rmapb\r"), io.Discard, nil)
+
+	for range 4 {
+		if _, err := editor.ReadLine("> "); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	expected := []string{"map", "mapb"}
+	actual := editor.History().Entries()
+	if strings.Join(actual, ",") != strings.Join(expected, ",") {
+		t.Errorf("expected history %v, got %v", expected, actual)
+	}
+}
+
+func TestReadLineComplete(t *testing.T) {
+	// Дополняем последнее слово из фиксированного списка без учета регистра
+	completer := func(line string) (int, []string) {
+		start := strings.LastIndex(line, " ") + 1
+		words := []string{"catch", "canalave-city-area", "canalave-city-area-2", "explore"}
+		var candidates []string
+		for _, word := range words {
+			if strings.HasPrefix(word, strings.ToLower(line[start:])) {
+				candidates = append(candidates, word)
+			}
+		}
+		return start, candidates
+	}
+
+	cases := []struct {
+		input    string
+		expected string
+	}{
+		{input: "ex\t\r", expected: "explore "},
+		{input: "explore can\t\r", expected: "explore canalave-city-area"},
+		{input: "explore ca\t\t\r", expected: "explore ca"},
+		{input: "zz\t\r", expected: "zz"},
+		{input: "EX\t\r", expected: "explore "},
+		{input: "explore CAN\t\r", expected: "explore canalave-city-area"},
+		{input: "cat pikachu\x01\x06\x06\x06\t\r", expected: "catch pikachu"},
+	}
+
+	for _, c := range cases {
+		var out strings.Builder
+		editor := New(strings.NewReader(c.input), &out, nil)
+		editor.SetCompleter(completer)
+
+		actual, err := editor.ReadLine("> ")
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", c.input, err)
+			continue
+		}
+		if actual != c.expected {
+			t.Errorf("%q: expected %q, got %q", c.input, c.expected, actual)
+		}
+	}
+
+	// Второй Tab без прогресса выводит варианты
+	var out strings.Builder
+	editor := New(strings.NewReader("ca\t\t\r"), &out, nil)
+	editor.SetCompleter(completer)
+	if _, err := editor.ReadLine("> "); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out.String(), "canalave-city-area-2") {
+		t.Errorf("expected candidates to be listed, got %q", out.String())
+	}
+}
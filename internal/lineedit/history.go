@@ -0,0 +1,132 @@
+package lineedit
+
+import (
+	"bufio"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Сколько строк истории хранится по умолчанию
+const DefaultHistorySize = 1000
+
+// History - список введенных строк, от старых к новым
+type History struct {
+	entries []string
+	max     int
+}
+
+// NewHistory создает пустую историю не длиннее max строк
+func NewHistory(max int) *History {
+	if max <= 0 {
+		max = DefaultHistorySize
+	}
+	return &History{max: max}
+}
+
+// DefaultHistoryPath возвращает путь к файлу истории (~/.pokedex_history)
+func DefaultHistoryPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".pokedex_history")
+}
+
+// LoadHistory читает историю из файла, отсутствие файла не ошибка
+func LoadHistory(path string, max int) (*History, error) {
+	h := NewHistory(max)
+	if path == "" {
+		return h, nil
+	}
+
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return h, nil
+	}
+	if err != nil {
+		return h, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		h.Add(scanner.Text())
+	}
+	return h, scanner.Err()
+}
+
+// Add добавляет строку в конец истории.
+// Пустые строки и повтор предыдущей строки не сохраняются
+func (h *History) Add(line string) {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return
+	}
+	if n := len(h.entries); n > 0 && h.entries[n-1] == line {
+		return
+	}
+
+	h.entries = append(h.entries, line)
+	if len(h.entries) > h.max {
+		h.entries = h.entries[len(h.entries)-h.max:]
+	}
+}
+
+// Len возвращает количество строк в истории
+func (h *History) Len() int {
+	return len(h.entries)
+}
+
+// Entries возвращает копию истории
+func (h *History) Entries() []string {
+	return append([]string(nil), h.entries...)
+}
+
+// Save записывает историю в файл через временный
+func (h *History) Save(path string) error {
+	if path == "" {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(tmp)
+	for _, line := range h.entries {
+		w.WriteString(line)
+		w.WriteByte('\n')
+	}
+	err = w.Flush()
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), 0o600)
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+	return err
+}
+
+// search ищет самую новую строку раньше from, в которой есть query.
+// Возвращает -1, если ничего не нашлось
+func (h *History) search(query string, from int) int {
+	for i := min(from, len(h.entries)) - 1; i >= 0; i-- {
+		if strings.Contains(h.entries[i], query) {
+			return i
+		}
+	}
+	return -1
+}
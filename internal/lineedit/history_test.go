@@ -0,0 +1,47 @@
+package lineedit
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHistorySaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history")
+
+	history := NewHistory(3)
+	for _, line := range []string{"map", "explore area", "catch pikachu", "inspect pikachu"} {
+		history.Add(line)
+	}
+	if err := history.Save(path); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("stat: %v", err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("expected mode 0600, got %v", info.Mode().Perm())
+	}
+
+	loaded, err := LoadHistory(path, 3)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	expected := "explore area,catch pikachu,inspect pikachu"
+	if actual := strings.Join(loaded.Entries(), ","); actual != expected {
+		t.Errorf("expected %q, got %q", expected, actual)
+	}
+}
+
+func TestLoadHistoryMissing(t *testing.T) {
+	history, err := LoadHistory(filepath.Join(t.TempDir(), "missing"), 10)
+	if err != nil {
+		t.Fatalf("expected no error for missing file, got %v", err)
+	}
+	if history.Len() != 0 {
+		t.Errorf("expected empty history, got %d entries", history.Len())
+	}
+}
@@ -0,0 +1,238 @@
+package pokeapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/CodeHunt7/go-pokedex/internal/pokecache"
+)
+
+// Адрес PokeAPI по умолчанию
+const DefaultBaseURL = "https://pokeapi.co/api/v2/"
+
+// Списки локаций почти не меняются, поэтому храним их дольше остального
+const LocationListTTL = 24 * time.Hour
+
+// Полные списки имен для автодополнения обновляются раз в неделю
+const NameIndexTTL = 7 * 24 * time.Hour
+
+// Лимит, при котором PokeAPI отдает список ресурсов одной страницей
+const nameIndexLimit = 100000
+
+// Сколько ждать ответа API вместе со всеми повторами
+const DefaultTimeout = 30 * time.Second
+
+// Client ходит в PokeAPI и складывает ответы в кэш
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	cache      *pokecache.Cache
+
+	// Одинаковые запросы в полете и фоновые обновления кэша
+	flights   flightGroup
+	refreshes sync.WaitGroup
+}
+
+// NewClient создает клиента для заданного адреса API.
+// Если httpClient равен nil, используется клиент с таймаутом
+// и повторами по умолчанию
+func NewClient(baseURL string, httpClient *http.Client, cache *pokecache.Cache) *Client {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	if !strings.HasSuffix(baseURL, "/") {
+		baseURL += "/"
+	}
+	if httpClient == nil {
+		httpClient = &http.Client{
+			Timeout:   DefaultTimeout,
+			Transport: NewRetryTransport(nil, DefaultMaxAttempts),
+		}
+	}
+
+	return &Client{
+		baseURL:    baseURL,
+		httpClient: httpClient,
+		cache:      cache,
+	}
+}
+
+// BaseURL возвращает адрес API, к которому обращается клиент
+func (c *Client) BaseURL() string {
+	return c.baseURL
+}
+
+// getJSON берет ответ из кэша или из API и распаковывает его в v.
+// Устаревший ответ из кэша отдается сразу, а обновляется в фоне.
+// ttl - сколько ответ считается свежим, 0 означает интервал кэша.
+// Отмена ctx прерывает запрос к API
+func (c *Client) getJSON(ctx context.Context, url string, ttl time.Duration, v any) error {
+	// Проверяем есть ли ответ в кеше
+	body, fresh, inCache := c.cache.GetStale(url)
+
+	// Ответ проверяем распаковкой в новое значение того же типа, что и v,
+	// чтобы не класть в кэш то, что потом не удастся распаковать
+	check := func(body []byte) error {
+		return json.Unmarshal(body, reflect.New(reflect.TypeOf(v).Elem()).Interface())
+	}
+
+	if inCache { // В кеше есть, используем его
+		if err := json.Unmarshal(body, v); err != nil {
+			return &DecodeError{URL: url, Err: err}
+		}
+		if !fresh {
+			c.refresh(url, ttl, check)
+		}
+		return nil
+	}
+
+	// В кеше нет, делаем запрос
+	body, err := c.load(ctx, url, ttl, check)
+	if err != nil {
+		return err
+	}
+
+	// Распаковываем JSON в стуктуру
+	if err := json.Unmarshal(body, v); err != nil {
+		return &DecodeError{URL: url, Err: err}
+	}
+	return nil
+}
+
+// refresh обновляет элемент кэша в фоне. Обновление не зависит
+// от контекста команды, которая его запустила
+func (c *Client) refresh(url string, ttl time.Duration, check func([]byte) error) {
+	c.refreshes.Add(1)
+
+	go func() {
+		defer c.refreshes.Done()
+
+		// Ошибку игнорируем: устаревший ответ уже отдан, попробуем в следующий раз
+		c.load(context.Background(), url, ttl, check)
+	}()
+}
+
+// load скачивает ответ и кладет его в кэш. Одновременные вызовы
+// для одной ссылки делят между собой один запрос. Вызов перестает его ждать
+// при отмене своего контекста, а запрос прерывается, только когда
+// отменены все вызовы, которые его ждали. check проверяет тело ответа
+// перед записью в кэш
+func (c *Client) load(ctx context.Context, url string, ttl time.Duration, check func([]byte) error) ([]byte, error) {
+	body, err, _ := c.flights.do(ctx, url, func(ctx context.Context) ([]byte, error) {
+		// Пока ждали очереди, ответ мог положить в кэш предыдущий запрос.
+		// Сам поиск уже посчитан в getJSON, поэтому счетчики не трогаем
+		if body, ok := c.cache.Peek(url); ok {
+			return body, nil
+		}
+
+		// Устаревший ответ с валидаторами проверяем условным запросом
+		cached, _ := c.cache.Validators(url)
+		body, validators, err := c.fetch(ctx, url, cached)
+		if errors.Is(err, errNotModified) {
+			if body, ok := c.cache.Touch(url); ok {
+				return body, nil
+			}
+			// Пока шел запрос, элемент успели вытеснить, скачиваем заново
+			body, validators, err = c.fetch(ctx, url, pokecache.Validators{})
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		// В кеш кладем только то, что удастся распаковать
+		if err := check(body); err != nil {
+			return nil, &DecodeError{URL: url, Err: err}
+		}
+		c.cache.AddWithValidators(url, body, ttl, validators)
+
+		return body, nil
+	})
+
+	return body, err
+}
+
+// fetch делает GET запрос и возвращает тело ответа с его валидаторами.
+// Если переданы валидаторы сохраненного ответа, запрос делается условным,
+// и на ответ 304 возвращается errNotModified. Остальные ошибки
+// возвращаются типами из errors.go, отмена ctx - как есть
+func (c *Client) fetch(ctx context.Context, url string, cached pokecache.Validators) ([]byte, pokecache.Validators, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, pokecache.Validators{}, err
+	}
+	if cached.ETag != "" {
+		req.Header.Set("If-None-Match", cached.ETag)
+	}
+	if cached.LastModified != "" {
+		req.Header.Set("If-Modified-Since", cached.LastModified)
+	}
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, pokecache.Validators{}, networkError(ctx, url, err)
+	}
+	defer res.Body.Close()
+
+	switch {
+	case res.StatusCode == http.StatusNotModified:
+		return nil, pokecache.Validators{}, errNotModified
+	case res.StatusCode == http.StatusNotFound:
+		return nil, pokecache.Validators{}, &NotFoundError{URL: url}
+	case res.StatusCode == http.StatusTooManyRequests:
+		wait, _ := retryAfter(res)
+		return nil, pokecache.Validators{}, &RateLimitedError{URL: url, RetryAfter: wait}
+	case res.StatusCode > 299:
+		return nil, pokecache.Validators{}, &UpstreamError{URL: url, StatusCode: res.StatusCode}
+	}
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, pokecache.Validators{}, networkError(ctx, url, err)
+	}
+
+	validators := pokecache.Validators{
+		ETag:         res.Header.Get("ETag"),
+		LastModified: res.Header.Get("Last-Modified"),
+	}
+	return body, validators, nil
+}
+
+// namesURL возвращает ссылку на полный список ресурсов одного вида
+func (c *Client) namesURL(resource string) string {
+	return fmt.Sprintf("%s%s/?offset=0&limit=%d", c.baseURL, resource, nameIndexLimit)
+}
+
+// listNames получает имена всех ресурсов одного вида одним запросом
+func (c *Client) listNames(ctx context.Context, resource string) ([]string, error) {
+	var list NamedResourceList
+	if err := c.getJSON(ctx, c.namesURL(resource), NameIndexTTL, &list); err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(list.Results))
+	for _, result := range list.Results {
+		names = append(names, result.Name)
+	}
+	return names, nil
+}
+
+// networkError оборачивает ошибку транспорта. Отмену команды
+// и промах снимка в режиме без сети не выдаем за сетевую ошибку
+func networkError(ctx context.Context, url string, err error) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	var offline *OfflineError
+	if errors.As(err, &offline) {
+		return offline
+	}
+	return &NetworkError{URL: url, Err: err}
+}
@@ -0,0 +1,535 @@
+package pokeapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/CodeHunt7/go-pokedex/internal/pokecache"
+)
+
+func TestGetPokemon(t *testing.T) {
+	hits := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		if r.URL.Path != "/pokemon/pikachu/" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, `{"name": "pikachu", "base_experience": 112}`)
+	}))
+	defer server.Close()
+
+	cache := pokecache.NewCache(time.Minute)
+	defer cache.Close()
+	client := NewClient(server.URL, server.Client(), cache)
+
+	for i := 0; i < 2; i++ {
+		pokemon, err := client.GetPokemon(context.Background(), "pikachu")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if pokemon.Name != "pikachu" || pokemon.BaseExperience != 112 {
+			t.Errorf("unexpected pokemon: %s %d", pokemon.Name, pokemon.BaseExperience)
+		}
+	}
+
+	// Второй запрос должен прийти из кэша
+	if hits != 1 {
+		t.Errorf("expected 1 request to the server, got %d", hits)
+	}
+
+	_, err := client.GetPokemon(context.Background(), "missingno")
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestListLocationAreas(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/location-area/" || r.URL.Query().Get("offset") != "0" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, `{"count": 2, "next": "next-page", "previous": null,
+			"results": [{"name": "canalave-city-area"}, {"name": "eterna-city-area"}]}`)
+	}))
+	defer server.Close()
+
+	cache := pokecache.NewCache(time.Minute)
+	defer cache.Close()
+	client := NewClient(server.URL+"/", server.Client(), cache)
+
+	locations, err := client.ListLocationAreas(context.Background(), "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(locations.Results) != 2 || locations.Results[0].Name != "canalave-city-area" {
+		t.Errorf("unexpected locations: %v", locations.Results)
+	}
+	if locations.Next != "next-page" {
+		t.Errorf("expected next page link, got %q", locations.Next)
+	}
+}
+
+func TestStaleWhileRevalidate(t *testing.T) {
+	hits := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		fmt.Fprintf(w, `{"name": "pikachu", "base_experience": %d}`, 100+hits)
+	}))
+	defer server.Close()
+
+	clock := pokecache.NewFakeClock(time.Now())
+	cache := pokecache.NewCache(time.Minute, pokecache.WithClock(clock), pokecache.WithStaleWhileRevalidate(time.Hour))
+	defer cache.Close()
+	client := NewClient(server.URL, server.Client(), cache)
+
+	if _, err := client.GetPokemon(context.Background(), "pikachu"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	clock.Advance(2 * time.Minute)
+
+	// Устаревший ответ отдается сразу, новый приходит в фоне
+	pokemon, err := client.GetPokemon(context.Background(), "pikachu")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if pokemon.BaseExperience != 101 {
+		t.Errorf("expected stale response, got base experience %d", pokemon.BaseExperience)
+	}
+	client.refreshes.Wait()
+
+	pokemon, err = client.GetPokemon(context.Background(), "pikachu")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if pokemon.BaseExperience != 102 {
+		t.Errorf("expected refreshed response, got base experience %d", pokemon.BaseExperience)
+	}
+	if hits != 2 {
+		t.Errorf("expected 2 requests to the server, got %d", hits)
+	}
+}
+
+func TestConditionalRevalidation(t *testing.T) {
+	const etag = `"v1"`
+	const lastModified = "Mon, 02 Jan 2006 15:04:05 GMT"
+
+	cases := []struct {
+		name      string
+		headers   map[string]string
+		condition func(r *http.Request) bool
+	}{
+		{
+			name:      "etag",
+			headers:   map[string]string{"ETag": etag},
+			condition: func(r *http.Request) bool { return r.Header.Get("If-None-Match") == etag },
+		},
+		{
+			name:      "last-modified",
+			headers:   map[string]string{"Last-Modified": lastModified},
+			condition: func(r *http.Request) bool { return r.Header.Get("If-Modified-Since") == lastModified },
+		},
+	}
+
+	for _, c := range cases {
+		full, notModified := 0, 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			for key, value := range c.headers {
+				w.Header().Set(key, value)
+			}
+			if c.condition(r) {
+				notModified++
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			full++
+			fmt.Fprint(w, `{"name": "pikachu", "base_experience": 112}`)
+		}))
+
+		clock := pokecache.NewFakeClock(time.Now())
+		cache := pokecache.NewCache(time.Minute, pokecache.WithClock(clock), pokecache.WithStaleWhileRevalidate(time.Hour))
+		client := NewClient(server.URL, server.Client(), cache)
+
+		if _, err := client.GetPokemon(context.Background(), "pikachu"); err != nil {
+			t.Fatalf("%s: unexpected error: %v", c.name, err)
+		}
+		clock.Advance(2 * time.Minute)
+
+		// Устаревший ответ проверяется условным запросом в фоне
+		if _, err := client.GetPokemon(context.Background(), "pikachu"); err != nil {
+			t.Fatalf("%s: unexpected error: %v", c.name, err)
+		}
+		client.refreshes.Wait()
+
+		// После 304 ответ снова свежий и берется из кэша без запросов
+		pokemon, err := client.GetPokemon(context.Background(), "pikachu")
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", c.name, err)
+		}
+		if pokemon.BaseExperience != 112 {
+			t.Errorf("%s: expected cached response, got base experience %d", c.name, pokemon.BaseExperience)
+		}
+		if full != 1 || notModified != 1 {
+			t.Errorf("%s: expected 1 full and 1 conditional request, got %d and %d", c.name, full, notModified)
+		}
+
+		cache.Close()
+		server.Close()
+	}
+}
+
+func TestRevalidateWithoutStaleWindow(t *testing.T) {
+	const etag = `"v1"`
+
+	full, notModified := 0, 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", etag)
+		if r.Header.Get("If-None-Match") == etag {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		full++
+		fmt.Fprint(w, `{"name": "pikachu", "base_experience": 112}`)
+	}))
+	defer server.Close()
+
+	clock := pokecache.NewFakeClock(time.Now())
+	cache := pokecache.NewCache(time.Minute, pokecache.WithClock(clock), pokecache.WithStaleWhileRevalidate(0))
+	// Останавливаем очистку, чтобы истекший элемент дождался следующего запроса
+	cache.Close()
+	client := NewClient(server.URL, server.Client(), cache)
+
+	if _, err := client.GetPokemon(context.Background(), "pikachu"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	clock.Advance(2 * time.Minute)
+
+	// Устаревший ответ не отдается, но проверяется условным запросом
+	pokemon, err := client.GetPokemon(context.Background(), "pikachu")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if pokemon.BaseExperience != 112 {
+		t.Errorf("expected cached response, got base experience %d", pokemon.BaseExperience)
+	}
+	if full != 1 || notModified != 1 {
+		t.Errorf("expected 1 full and 1 conditional request, got %d and %d", full, notModified)
+	}
+}
+
+func TestWrongShapeNotCached(t *testing.T) {
+	hits := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		fmt.Fprint(w, `{"name": 25}`)
+	}))
+	defer server.Close()
+
+	cache := pokecache.NewCache(time.Minute)
+	defer cache.Close()
+	client := NewClient(server.URL, server.Client(), cache)
+
+	// Ответ, который не распаковывается, каждый раз запрашивается заново
+	for range 2 {
+		if _, err := client.GetPokemon(context.Background(), "pikachu"); !errors.Is(err, ErrDecode) {
+			t.Errorf("expected ErrDecode, got %v", err)
+		}
+	}
+	if hits != 2 || cache.Len() != 0 {
+		t.Errorf("expected 2 requests and nothing cached, got %d and %d entries", hits, cache.Len())
+	}
+}
+
+func TestCacheStats(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"name": "pikachu", "base_experience": 112}`)
+	}))
+	defer server.Close()
+
+	clock := pokecache.NewFakeClock(time.Now())
+	cache := pokecache.NewCache(time.Minute, pokecache.WithClock(clock), pokecache.WithStaleWhileRevalidate(time.Hour))
+	defer cache.Close()
+	client := NewClient(server.URL, server.Client(), cache)
+
+	// Каждый поиск в кэше считается один раз, даже если за ним идет запрос
+	cases := []struct {
+		name    string
+		advance time.Duration
+		hits    int
+		misses  int
+	}{
+		{name: "miss", hits: 0, misses: 1},
+		{name: "hit", hits: 1, misses: 1},
+		{name: "stale hit", advance: 2 * time.Minute, hits: 2, misses: 1},
+	}
+
+	for _, c := range cases {
+		clock.Advance(c.advance)
+		if _, err := client.GetPokemon(context.Background(), "pikachu"); err != nil {
+			t.Fatalf("%s: unexpected error: %v", c.name, err)
+		}
+		client.refreshes.Wait()
+
+		stats := cache.Stats()
+		if stats.Hits != c.hits || stats.Misses != c.misses {
+			t.Errorf("%s: expected %d hits and %d misses, got %d and %d", c.name, c.hits, c.misses, stats.Hits, stats.Misses)
+		}
+	}
+}
+
+func TestConcurrentFetchesShareRequest(t *testing.T) {
+	const callers = 10
+	var hits atomic.Int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		<-release
+		fmt.Fprint(w, `{"name": "canalave-city-area"}`)
+	}))
+	defer server.Close()
+
+	cache := pokecache.NewCache(time.Minute)
+	defer cache.Close()
+	client := NewClient(server.URL, server.Client(), cache)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := client.GetLocationArea(context.Background(), "canalave-city-area")
+			errs <- err
+		}()
+	}
+
+	// Ждем, пока первый запрос дойдет до сервера, и отпускаем его
+	for hits.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+	if hits.Load() != 1 {
+		t.Errorf("expected 1 request to the server, got %d", hits.Load())
+	}
+}
+
+func TestCancelledRequest(t *testing.T) {
+	// Сервер отвечает только после отмены запроса
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	t.Cleanup(server.Close)
+	t.Cleanup(func() { close(release) })
+
+	cache := pokecache.NewCache(time.Minute)
+	t.Cleanup(cache.Close)
+	client := NewClient(server.URL, server.Client(), cache)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+
+	_, err := client.GetPokemon(ctx, "pikachu")
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if cache.Len() != 0 {
+		t.Errorf("cancelled response should not be cached")
+	}
+}
+
+func TestCancelWhileWaitingForSharedRequest(t *testing.T) {
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		<-release
+		fmt.Fprint(w, `{"name": "pikachu", "base_experience": 112}`)
+	}))
+	t.Cleanup(server.Close)
+
+	cache := pokecache.NewCache(time.Minute)
+	t.Cleanup(cache.Close)
+	client := NewClient(server.URL, server.Client(), cache)
+
+	// Запрос без отмены, как фоновое обновление
+	background := make(chan error, 1)
+	go func() {
+		_, err := client.GetPokemon(context.Background(), "pikachu")
+		background <- err
+	}()
+	<-started
+
+	// Команда присоединяется к нему и отменяется, не дожидаясь ответа
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+	if _, err := client.GetPokemon(ctx, "pikachu"); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+
+	// Общий запрос при этом не прерывается
+	close(release)
+	if err := <-background; err != nil {
+		t.Errorf("expected background request to finish, got %v", err)
+	}
+}
+
+func TestCancelFirstOfSharedRequest(t *testing.T) {
+	var hits atomic.Int32
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		started <- struct{}{}
+		<-release
+		fmt.Fprint(w, `{"name": "pikachu", "base_experience": 112}`)
+	}))
+	t.Cleanup(server.Close)
+
+	cache := pokecache.NewCache(time.Minute)
+	t.Cleanup(cache.Close)
+	client := NewClient(server.URL, server.Client(), cache)
+
+	// Первая команда начинает запрос
+	ctx, cancel := context.WithCancel(context.Background())
+	first := make(chan error, 1)
+	go func() {
+		_, err := client.GetPokemon(ctx, "pikachu")
+		first <- err
+	}()
+	<-started
+
+	// Вторая присоединяется к нему без отмены
+	second := make(chan error, 1)
+	go func() {
+		_, err := client.GetPokemon(context.Background(), "pikachu")
+		second <- err
+	}()
+	for waiters(client) < 2 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// Отмена первой команды не прерывает запрос для второй
+	cancel()
+	if err := <-first; !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	close(release)
+	if err := <-second; err != nil {
+		t.Errorf("expected shared request to finish, got %v", err)
+	}
+	if hits.Load() != 1 {
+		t.Errorf("expected 1 request to the server, got %d", hits.Load())
+	}
+}
+
+// waiters возвращает, сколько вызовов ждут запросы в полете
+func waiters(client *Client) int {
+	client.flights.mu.Lock()
+	defer client.flights.mu.Unlock()
+
+	n := 0
+	for _, call := range client.flights.calls {
+		n += call.waiters
+	}
+	return n
+}
+
+func TestErrorTypes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch strings.TrimSuffix(r.URL.Path, "/") {
+		case "/pokemon/missingno":
+			http.NotFound(w, r)
+		case "/pokemon/limited":
+			w.Header().Set("Retry-After", "7")
+			w.WriteHeader(http.StatusTooManyRequests)
+		case "/pokemon/broken":
+			w.WriteHeader(http.StatusBadGateway)
+			fmt.Fprint(w, "<html><body>Bad Gateway</body></html>")
+		case "/pokemon/garbage":
+			fmt.Fprint(w, "<html>")
+		case "/pokemon/wrong-shape":
+			fmt.Fprint(w, `{"name": 25}`)
+		}
+	}))
+	defer server.Close()
+
+	cache := pokecache.NewCache(time.Minute)
+	defer cache.Close()
+	client := NewClient(server.URL, server.Client(), cache)
+
+	cases := []struct {
+		name     string
+		expected error
+		check    func(err error) bool
+	}{
+		{name: "missingno", expected: ErrNotFound, check: func(err error) bool {
+			var e *NotFoundError
+			if !errors.As(err, &e) || !strings.Contains(e.URL, "/pokemon/missingno") {
+				return false
+			}
+			kind, name := e.Resource()
+			return kind == "pokemon" && name == "missingno"
+		}},
+		{name: "limited", expected: ErrRateLimited, check: func(err error) bool {
+			var e *RateLimitedError
+			return errors.As(err, &e) && e.RetryAfter == 7*time.Second
+		}},
+		{name: "broken", expected: ErrUpstream, check: func(err error) bool {
+			var e *UpstreamError
+			return errors.As(err, &e) && e.StatusCode == http.StatusBadGateway && !strings.Contains(err.Error(), "html")
+		}},
+		{name: "garbage", expected: ErrDecode, check: func(err error) bool {
+			var e *DecodeError
+			return errors.As(err, &e)
+		}},
+		{name: "wrong-shape", expected: ErrDecode, check: func(err error) bool {
+			var e *json.UnmarshalTypeError
+			return errors.As(err, &e)
+		}},
+	}
+
+	for _, c := range cases {
+		_, err := client.GetPokemon(context.Background(), c.name)
+		if !errors.Is(err, c.expected) {
+			t.Errorf("%s: expected %v, got %v", c.name, c.expected, err)
+			continue
+		}
+		if !c.check(err) {
+			t.Errorf("%s: unexpected error details: %#v", c.name, err)
+		}
+	}
+
+	// Недоступный сервер - сетевая ошибка, отмена - нет
+	server.Close()
+	if _, err := client.GetPokemon(context.Background(), "pikachu"); !errors.Is(err, ErrNetwork) {
+		t.Errorf("expected ErrNetwork, got %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := client.GetPokemon(ctx, "pidgey"); errors.Is(err, ErrNetwork) || !errors.Is(err, context.Canceled) {
+		t.Errorf("expected only context.Canceled, got %v", err)
+	}
+}
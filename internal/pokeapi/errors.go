@@ -0,0 +1,135 @@
+package pokeapi
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Виды ошибок API для проверки через errors.Is.
+// Подробности достаются через errors.As из соответствующих типов
+var (
+	ErrNotFound    = errors.New("resource not found")
+	ErrRateLimited = errors.New("rate limited")
+	ErrUpstream    = errors.New("upstream error")
+	ErrDecode      = errors.New("invalid response")
+	ErrNetwork     = errors.New("network error")
+	ErrOffline     = errors.New("not available offline")
+)
+
+// errNotModified - сервер подтвердил, что сохраненный ответ не изменился
+var errNotModified = errors.New("not modified")
+
+// NotFoundError - PokeAPI ответил 404
+type NotFoundError struct {
+	URL string
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("%v: %s", ErrNotFound, e.URL)
+}
+
+func (e *NotFoundError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+// Resource возвращает вид ресурса и имя из ссылки:
+// .../pokemon/pikachu/ -> pokemon, pikachu.
+// Если ссылка не похожа на ресурс, оба значения пустые
+func (e *NotFoundError) Resource() (kind, name string) {
+	u, err := url.Parse(e.URL)
+	if err != nil {
+		return "", ""
+	}
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(segments) < 2 || segments[len(segments)-1] == "" {
+		return "", ""
+	}
+	return segments[len(segments)-2], segments[len(segments)-1]
+}
+
+// RateLimitedError - PokeAPI ответил 429 и после всех повторов
+type RateLimitedError struct {
+	URL        string
+	RetryAfter time.Duration // 0, если сервер не сказал, сколько ждать
+}
+
+func (e *RateLimitedError) Error() string {
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("%v: %s, retry after %s", ErrRateLimited, e.URL, e.RetryAfter)
+	}
+	return fmt.Sprintf("%v: %s", ErrRateLimited, e.URL)
+}
+
+func (e *RateLimitedError) Is(target error) bool {
+	return target == ErrRateLimited
+}
+
+// UpstreamError - PokeAPI ответил неожиданным статусом, обычно 5xx.
+// Тело ответа не сохраняется: там бывает целая HTML страница
+type UpstreamError struct {
+	URL        string
+	StatusCode int
+}
+
+func (e *UpstreamError) Error() string {
+	return fmt.Sprintf("%v: %s responded with status %d", ErrUpstream, e.URL, e.StatusCode)
+}
+
+func (e *UpstreamError) Is(target error) bool {
+	return target == ErrUpstream
+}
+
+// DecodeError - ответ пришел, но это не тот JSON, который ждали
+type DecodeError struct {
+	URL string
+	Err error // nil, если тело вообще не JSON
+}
+
+func (e *DecodeError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("%v from %s: not JSON", ErrDecode, e.URL)
+	}
+	return fmt.Sprintf("%v from %s: %v", ErrDecode, e.URL, e.Err)
+}
+
+func (e *DecodeError) Is(target error) bool {
+	return target == ErrDecode
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// NetworkError - запрос не дошел до PokeAPI или ответ оборвался
+type NetworkError struct {
+	URL string
+	Err error
+}
+
+func (e *NetworkError) Error() string {
+	return fmt.Sprintf("%v: %s: %v", ErrNetwork, e.URL, e.Err)
+}
+
+func (e *NetworkError) Is(target error) bool {
+	return target == ErrNetwork
+}
+
+func (e *NetworkError) Unwrap() error {
+	return e.Err
+}
+
+// OfflineError - в режиме без сети ответа нет в снимке
+type OfflineError struct {
+	URL string
+}
+
+func (e *OfflineError) Error() string {
+	return fmt.Sprintf("%v: %s is not in the snapshot", ErrOffline, e.URL)
+}
+
+func (e *OfflineError) Is(target error) bool {
+	return target == ErrOffline
+}
@@ -0,0 +1,74 @@
+package pokeapi
+
+import (
+	"context"
+	"sync"
+)
+
+// flightGroup не дает делать одинаковые запросы одновременно:
+// пока запрос по ключу в полете, остальные вызовы ждут его результат
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*flightCall
+}
+
+// flightCall - запрос в полете
+type flightCall struct {
+	done    chan struct{} // закрывается, когда результат готов
+	cancel  context.CancelFunc
+	waiters int // сколько вызовов еще ждут результат
+	val     []byte
+	err     error
+}
+
+// do выполняет fn для ключа, если такой запрос еще не выполняется,
+// иначе ждет уже идущий запрос и возвращает его результат.
+// fn получает контекст, не зависящий от отмены отдельного вызова:
+// ожидающий вызов прерывается отменой своего ctx, а сам запрос
+// отменяется, только когда его перестали ждать все вызовы.
+// shared сообщает, что результат получили несколько вызовов
+func (g *flightGroup) do(ctx context.Context, key string, fn func(ctx context.Context) ([]byte, error)) (val []byte, err error, shared bool) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*flightCall)
+	}
+	call, shared := g.calls[key]
+	if !shared {
+		flightCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		call = &flightCall{done: make(chan struct{}), cancel: cancel}
+		g.calls[key] = call
+		go g.run(flightCtx, key, call, fn)
+	}
+	call.waiters++
+	g.mu.Unlock()
+
+	select {
+	case <-call.done:
+		return call.val, call.err, shared
+	case <-ctx.Done():
+		g.mu.Lock()
+		call.waiters--
+		if call.waiters == 0 {
+			// Результат больше никому не нужен, новые вызовы начнут заново
+			call.cancel()
+			if g.calls[key] == call {
+				delete(g.calls, key)
+			}
+		}
+		g.mu.Unlock()
+		return nil, ctx.Err(), shared
+	}
+}
+
+// run выполняет запрос и отдает результат всем, кто его ждет
+func (g *flightGroup) run(ctx context.Context, key string, call *flightCall, fn func(ctx context.Context) ([]byte, error)) {
+	call.val, call.err = fn(ctx)
+	call.cancel()
+
+	g.mu.Lock()
+	if g.calls[key] == call {
+		delete(g.calls, key)
+	}
+	g.mu.Unlock()
+	close(call.done)
+}
@@ -0,0 +1,38 @@
+package pokeapi
+
+import "context"
+
+// Первая страница списка локаций относительно адреса API
+const firstLocationPage = "location-area/?offset=0&limit=20"
+
+// ListLocationAreas получает страницу списка локаций.
+// Пустой pageURL означает первую страницу
+func (c *Client) ListLocationAreas(ctx context.Context, pageURL string) (LocationAreaResponse, error) {
+	if pageURL == "" {
+		pageURL = c.baseURL + firstLocationPage
+	}
+
+	var locations LocationAreaResponse
+	if err := c.getJSON(ctx, pageURL, LocationListTTL, &locations); err != nil {
+		return LocationAreaResponse{}, err
+	}
+
+	return locations, nil
+}
+
+// GetLocationArea получает информацию о конкретной локации
+func (c *Client) GetLocationArea(ctx context.Context, name string) (ConcreteLocationResponce, error) {
+	url := c.baseURL + "location-area/" + name + "/"
+
+	var locationInfo ConcreteLocationResponce
+	if err := c.getJSON(ctx, url, 0, &locationInfo); err != nil {
+		return ConcreteLocationResponce{}, err
+	}
+
+	return locationInfo, nil
+}
+
+// ListLocationAreaNames получает названия всех локаций
+func (c *Client) ListLocationAreaNames(ctx context.Context) ([]string, error) {
+	return c.listNames(ctx, "location-area")
+}
@@ -0,0 +1,20 @@
+package pokeapi
+
+import "context"
+
+// GetPokemon получает информацию о покемоне по имени
+func (c *Client) GetPokemon(ctx context.Context, name string) (PokemonResponse, error) {
+	url := c.baseURL + "pokemon/" + name + "/"
+
+	var pokemonInfo PokemonResponse
+	if err := c.getJSON(ctx, url, 0, &pokemonInfo); err != nil {
+		return PokemonResponse{}, err
+	}
+
+	return pokemonInfo, nil
+}
+
+// ListPokemonNames получает имена всех покемонов национального покедекса
+func (c *Client) ListPokemonNames(ctx context.Context) ([]string, error) {
+	return c.listNames(ctx, "pokemon")
+}
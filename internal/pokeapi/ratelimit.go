@@ -0,0 +1,107 @@
+package pokeapi
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Ограничение запросов по умолчанию: PokeAPI просит не слать лишнего
+const (
+	DefaultRate  = 5.0 // запросов в секунду
+	DefaultBurst = 10  // запросов подряд без ожидания
+)
+
+// RateLimitTransport ограничивает частоту запросов к API ведром токенов:
+// ведро вмещает burst токенов и пополняется со скоростью rate в секунду,
+// каждый запрос забирает токен или ждет его.
+// Ответы из кэша сюда не доходят и лимит не расходуют
+type RateLimitTransport struct {
+	Base   http.RoundTripper        // nil - http.DefaultTransport
+	OnWait func(wait time.Duration) // вызывается, если запросу приходится ждать
+
+	mu     sync.Mutex
+	rate   float64 // токенов в секунду, 0 - без ограничения
+	burst  float64
+	tokens float64
+	last   time.Time
+	now    func() time.Time // подменяется в тестах
+}
+
+// NewRateLimitTransport создает ограничитель на rate запросов в секунду
+// с запасом burst. rate <= 0 отключает ограничение
+func NewRateLimitTransport(base http.RoundTripper, rate float64, burst int) *RateLimitTransport {
+	burst = max(burst, 1)
+	return &RateLimitTransport{
+		Base:   base,
+		rate:   max(rate, 0),
+		burst:  float64(burst),
+		tokens: float64(burst),
+		now:    time.Now,
+	}
+}
+
+// RoundTrip дожидается токена и выполняет запрос
+func (t *RateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	if wait := t.reserve(); wait > 0 {
+		if t.OnWait != nil {
+			t.OnWait(wait)
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			timer.Stop()
+			// Запрос не ушел, возвращаем токен
+			t.refund()
+			return nil, req.Context().Err()
+		}
+	}
+
+	return base.RoundTrip(req)
+}
+
+// reserve забирает токен и возвращает, сколько ждать, пока он появится.
+// Токены могут уйти в минус: следующие запросы встают в очередь за текущим
+func (t *RateLimitTransport) reserve() time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.rate == 0 {
+		return 0
+	}
+	t.fill()
+	t.tokens--
+	return t.waitFor(t.tokens)
+}
+
+// refund возвращает неиспользованный токен
+func (t *RateLimitTransport) refund() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.tokens = min(t.tokens+1, t.burst)
+}
+
+// fill пополняет ведро за время с прошлого обращения
+func (t *RateLimitTransport) fill() {
+	now := t.now()
+	if !t.last.IsZero() {
+		t.tokens = min(t.burst, t.tokens+now.Sub(t.last).Seconds()*t.rate)
+	}
+	t.last = now
+}
+
+// waitFor переводит недостачу токенов во время ожидания
+func (t *RateLimitTransport) waitFor(tokens float64) time.Duration {
+	if tokens >= 0 || t.rate == 0 {
+		return 0
+	}
+	return time.Duration(-tokens / t.rate * float64(time.Second))
+}
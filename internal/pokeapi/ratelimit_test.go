@@ -0,0 +1,116 @@
+package pokeapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/CodeHunt7/go-pokedex/internal/pokecache"
+)
+
+func TestRateLimitReserve(t *testing.T) {
+	clock := pokecache.NewFakeClock(time.Now())
+	limiter := NewRateLimitTransport(nil, 2, 3)
+	limiter.now = clock.Now
+
+	cases := []struct {
+		advance  time.Duration
+		expected time.Duration
+	}{
+		// Запас burst уходит без ожидания
+		{advance: 0, expected: 0},
+		{advance: 0, expected: 0},
+		{advance: 0, expected: 0},
+		// Дальше каждый запрос ждет полсекунды за предыдущим
+		{advance: 0, expected: 500 * time.Millisecond},
+		{advance: 0, expected: time.Second},
+		// За две секунды ведро пополняется на 4 токена, долг в 2 токена гасится
+		{advance: 2 * time.Second, expected: 0},
+		{advance: 0, expected: 0},
+		{advance: 0, expected: 500 * time.Millisecond},
+		// Ведро не наполняется больше burst
+		{advance: time.Hour, expected: 0},
+		{advance: 0, expected: 0},
+		{advance: 0, expected: 0},
+		{advance: 0, expected: 500 * time.Millisecond},
+	}
+
+	for i, c := range cases {
+		clock.Advance(c.advance)
+		if actual := limiter.reserve(); actual != c.expected {
+			t.Errorf("request %d: expected wait %v, got %v", i, c.expected, actual)
+		}
+	}
+}
+
+func TestRateLimitSkipsCacheHits(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		fmt.Fprint(w, `{"name": "pikachu"}`)
+	}))
+	t.Cleanup(server.Close)
+
+	waits := 0
+	limiter := NewRateLimitTransport(server.Client().Transport, 20, 1)
+	limiter.OnWait = func(time.Duration) { waits++ }
+
+	cache := pokecache.NewCache(time.Minute)
+	t.Cleanup(cache.Close)
+	client := NewClient(server.URL, &http.Client{Transport: limiter}, cache)
+
+	// Повторные запросы берутся из кэша и не ждут токена
+	for range 5 {
+		if _, err := client.GetPokemon(context.Background(), "pikachu"); err != nil {
+			t.Fatalf("get: %v", err)
+		}
+	}
+	if hits.Load() != 1 || waits != 0 {
+		t.Errorf("expected 1 request without waiting, got %d requests and %d waits", hits.Load(), waits)
+	}
+
+	// Разные запросы подряд упираются в лимит
+	for _, name := range []string{"pichu", "raichu"} {
+		if _, err := client.GetPokemon(context.Background(), name); err != nil {
+			t.Fatalf("get %s: %v", name, err)
+		}
+	}
+	if waits == 0 {
+		t.Errorf("expected requests beyond burst to wait")
+	}
+}
+
+func TestRateLimitCancel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{}`)
+	}))
+	t.Cleanup(server.Close)
+
+	limiter := NewRateLimitTransport(server.Client().Transport, 0.001, 1)
+	client := &http.Client{Transport: limiter}
+
+	// Первый запрос забирает единственный токен
+	res, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	res.Body.Close()
+
+	// Второй ждет очень долго и отменяется
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	if _, err := client.Do(req); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+
+	// Отмененный запрос вернул свой токен
+	if limiter.tokens < -0.01 {
+		t.Errorf("expected cancelled reservation to be refunded, tokens = %v", limiter.tokens)
+	}
+}
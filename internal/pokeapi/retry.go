@@ -0,0 +1,142 @@
+package pokeapi
+
+import (
+	"context"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Параметры повторов по умолчанию
+const (
+	DefaultMaxAttempts = 3
+	DefaultBaseDelay   = 500 * time.Millisecond
+	DefaultMaxDelay    = 10 * time.Second
+)
+
+// RetryTransport повторяет GET и HEAD запросы, которые упали с сетевой ошибкой
+// или временным ответом сервера (429, 500, 502, 503, 504).
+// Пауза между попытками растет экспоненциально со случайным разбросом,
+// а для 429 и 503 берется из заголовка Retry-After
+type RetryTransport struct {
+	Base        http.RoundTripper // nil - http.DefaultTransport
+	MaxAttempts int               // всего попыток вместе с первой
+	BaseDelay   time.Duration     // пауза перед первым повтором
+	MaxDelay    time.Duration     // предел паузы; если Retry-After просит ждать дольше, не повторяем
+
+	sleep func(ctx context.Context, d time.Duration) error // подменяется в тестах
+}
+
+// NewRetryTransport создает транспорт с паузами по умолчанию.
+// Если base равен nil, используется http.DefaultTransport
+func NewRetryTransport(base http.RoundTripper, maxAttempts int) *RetryTransport {
+	return &RetryTransport{
+		Base:        base,
+		MaxAttempts: maxAttempts,
+		BaseDelay:   DefaultBaseDelay,
+		MaxDelay:    DefaultMaxDelay,
+	}
+}
+
+// RoundTrip выполняет запрос, повторяя его при временных ошибках
+func (t *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	// Повторяем только идемпотентные запросы без тела
+	if (req.Method != http.MethodGet && req.Method != http.MethodHead) || (req.Body != nil && req.Body != http.NoBody) {
+		return base.RoundTrip(req)
+	}
+
+	for attempt := 1; ; attempt++ {
+		res, err := base.RoundTrip(req)
+		if attempt >= t.MaxAttempts || !retryable(req.Context(), res, err) {
+			return res, err
+		}
+
+		delay := t.backoff(attempt)
+		if res != nil {
+			if wait, ok := retryAfter(res); ok {
+				if wait > t.MaxDelay {
+					return res, nil
+				}
+				delay = wait
+			}
+			// Тело ответа нужно дочитать, чтобы соединение вернулось в пул
+			io.Copy(io.Discard, res.Body)
+			res.Body.Close()
+		}
+
+		if err := t.wait(req.Context(), delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// retryable решает, стоит ли повторять запрос
+func retryable(ctx context.Context, res *http.Response, err error) bool {
+	if err != nil {
+		// Отмененный запрос не повторяем
+		return ctx.Err() == nil
+	}
+
+	switch res.StatusCode {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// backoff возвращает паузу перед повтором номер attempt:
+// BaseDelay * 2^(attempt-1), не больше MaxDelay, со случайной половиной
+func (t *RetryTransport) backoff(attempt int) time.Duration {
+	delay := t.BaseDelay << (attempt - 1)
+	if delay > t.MaxDelay || delay <= 0 {
+		delay = t.MaxDelay
+	}
+	if delay < 2 {
+		return delay
+	}
+	return delay/2 + rand.N(delay/2)
+}
+
+// retryAfter разбирает Retry-After для ответов 429 и 503:
+// число секунд или дату
+func retryAfter(res *http.Response) (time.Duration, bool) {
+	if res.StatusCode != http.StatusTooManyRequests && res.StatusCode != http.StatusServiceUnavailable {
+		return 0, false
+	}
+
+	value := res.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0), true
+	}
+	return 0, false
+}
+
+// wait ждет паузу или отмену запроса
+func (t *RetryTransport) wait(ctx context.Context, d time.Duration) error {
+	if t.sleep != nil {
+		return t.sleep(ctx, d)
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,188 @@
+package pokeapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/CodeHunt7/go-pokedex/internal/pokecache"
+)
+
+// newFlakyServer отвечает ошибками failures раз, а потом нормально
+func newFlakyServer(t *testing.T, failures int32, fail func(w http.ResponseWriter)) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+
+	hits := &atomic.Int32{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hits.Add(1) <= failures {
+			fail(w)
+			return
+		}
+		fmt.Fprint(w, `{"name": "pikachu", "base_experience": 112}`)
+	}))
+	t.Cleanup(server.Close)
+
+	return server, hits
+}
+
+// newRetryClient собирает клиента с транспортом без настоящих пауз
+func newRetryClient(t *testing.T, server *httptest.Server, maxAttempts int) (*Client, *[]time.Duration) {
+	t.Helper()
+
+	delays := &[]time.Duration{}
+	transport := NewRetryTransport(server.Client().Transport, maxAttempts)
+	transport.sleep = func(ctx context.Context, d time.Duration) error {
+		*delays = append(*delays, d)
+		return ctx.Err()
+	}
+
+	cache := pokecache.NewCache(time.Minute)
+	t.Cleanup(cache.Close)
+
+	return NewClient(server.URL, &http.Client{Transport: transport}, cache), delays
+}
+
+func TestRetryTransientErrors(t *testing.T) {
+	cases := []struct {
+		name string
+		fail func(w http.ResponseWriter)
+	}{
+		{name: "503", fail: func(w http.ResponseWriter) { w.WriteHeader(http.StatusServiceUnavailable) }},
+		{name: "500", fail: func(w http.ResponseWriter) { w.WriteHeader(http.StatusInternalServerError) }},
+		{name: "429", fail: func(w http.ResponseWriter) { w.WriteHeader(http.StatusTooManyRequests) }},
+		{name: "connection reset", fail: func(w http.ResponseWriter) {
+			conn, _, _ := w.(http.Hijacker).Hijack()
+			conn.Close()
+		}},
+	}
+
+	for _, c := range cases {
+		server, hits := newFlakyServer(t, 2, c.fail)
+		client, delays := newRetryClient(t, server, 3)
+
+		pokemon, err := client.GetPokemon(context.Background(), "pikachu")
+		if err != nil {
+			t.Errorf("%s: expected success after retries, got %v", c.name, err)
+			continue
+		}
+		if pokemon.Name != "pikachu" {
+			t.Errorf("%s: expected pikachu, got %q", c.name, pokemon.Name)
+		}
+		if hits.Load() != 3 {
+			t.Errorf("%s: expected 3 requests, got %d", c.name, hits.Load())
+		}
+
+		// Паузы растут экспоненциально в пределах разброса
+		if len(*delays) != 2 {
+			t.Errorf("%s: expected 2 pauses, got %v", c.name, *delays)
+			continue
+		}
+		first, second := (*delays)[0], (*delays)[1]
+		if first < DefaultBaseDelay/2 || first > DefaultBaseDelay || second < DefaultBaseDelay || second > 2*DefaultBaseDelay {
+			t.Errorf("%s: unexpected backoff %v", c.name, *delays)
+		}
+	}
+}
+
+func TestRetryGivesUp(t *testing.T) {
+	server, hits := newFlakyServer(t, 100, func(w http.ResponseWriter) {
+		w.WriteHeader(http.StatusBadGateway)
+	})
+	client, _ := newRetryClient(t, server, 4)
+
+	_, err := client.GetPokemon(context.Background(), "pikachu")
+	if err == nil || !strings.Contains(err.Error(), "502") {
+		t.Errorf("expected status 502 error, got %v", err)
+	}
+	if hits.Load() != 4 {
+		t.Errorf("expected 4 requests, got %d", hits.Load())
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	server, hits := newFlakyServer(t, 1, func(w http.ResponseWriter) {
+		w.Header().Set("Retry-After", "2")
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+	client, delays := newRetryClient(t, server, 3)
+
+	if _, err := client.GetPokemon(context.Background(), "pikachu"); err != nil {
+		t.Fatalf("expected success, got %v", err)
+	}
+	if hits.Load() != 2 {
+		t.Errorf("expected 2 requests, got %d", hits.Load())
+	}
+	if len(*delays) != 1 || (*delays)[0] != 2*time.Second {
+		t.Errorf("expected a 2s pause from Retry-After, got %v", *delays)
+	}
+
+	// Слишком долгое ожидание не ждем, а отдаем ответ как есть
+	server, hits = newFlakyServer(t, 1, func(w http.ResponseWriter) {
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	client, _ = newRetryClient(t, server, 3)
+	if _, err := client.GetPokemon(context.Background(), "pikachu"); err == nil {
+		t.Errorf("expected error when Retry-After exceeds the maximum delay")
+	}
+	if hits.Load() != 1 {
+		t.Errorf("expected 1 request, got %d", hits.Load())
+	}
+}
+
+func TestRetryOnlyIdempotent(t *testing.T) {
+	server, hits := newFlakyServer(t, 1, func(w http.ResponseWriter) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	client, _ := newRetryClient(t, server, 3)
+
+	res, err := client.httpClient.Post(server.URL, "application/json", strings.NewReader("{}"))
+	if err != nil {
+		t.Fatalf("post: %v", err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusServiceUnavailable || hits.Load() != 1 {
+		t.Errorf("expected a single POST with status 503, got %d after %d requests", res.StatusCode, hits.Load())
+	}
+}
+
+func TestRetryNotFoundAndCancel(t *testing.T) {
+	server, hits := newFlakyServer(t, 100, func(w http.ResponseWriter) {
+		http.NotFound(w, nil)
+	})
+	client, _ := newRetryClient(t, server, 3)
+
+	if _, err := client.GetPokemon(context.Background(), "missingno"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+	if hits.Load() != 1 {
+		t.Errorf("404 should not be retried, got %d requests", hits.Load())
+	}
+
+	// Отмена во время паузы прекращает повторы
+	server, hits = newFlakyServer(t, 100, func(w http.ResponseWriter) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	transport := NewRetryTransport(server.Client().Transport, 5)
+	transport.sleep = func(context.Context, time.Duration) error {
+		cancel()
+		return context.Canceled
+	}
+	cache := pokecache.NewCache(time.Minute)
+	t.Cleanup(cache.Close)
+	client = NewClient(server.URL, &http.Client{Transport: transport}, cache)
+
+	if _, err := client.GetPokemon(ctx, "pikachu"); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if hits.Load() != 1 {
+		t.Errorf("expected 1 request before cancel, got %d", hits.Load())
+	}
+}
@@ -0,0 +1,134 @@
+package pokeapi
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Файл с описанием снимка внутри архива
+const snapshotManifest = "manifest.json"
+
+// SnapshotManifest описывает, откуда и когда снят архив
+type SnapshotManifest struct {
+	BaseURL   string    `json:"base_url"`
+	CreatedAt time.Time `json:"created_at"`
+	Entries   int       `json:"entries"`
+}
+
+// Snapshot - архив ответов PokeAPI для работы без сети.
+// Как http.RoundTripper он отвечает на запросы из архива,
+// а на все, чего в архиве нет, возвращает OfflineError
+type Snapshot struct {
+	Manifest SnapshotManifest
+
+	archive *zip.ReadCloser
+	files   map[string]*zip.File
+}
+
+// DefaultSnapshotPath возвращает путь к снимку по умолчанию
+// рядом с кэшем ($XDG_CACHE_HOME/pokedex/snapshot.zip на Linux)
+func DefaultSnapshotPath() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "pokedex", "snapshot.zip")
+}
+
+// OpenSnapshot открывает архив, собранный BuildSnapshot
+func OpenSnapshot(path string) (*Snapshot, error) {
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Snapshot{
+		archive: archive,
+		files:   make(map[string]*zip.File, len(archive.File)),
+	}
+	for _, file := range archive.File {
+		s.files[file.Name] = file
+	}
+
+	manifest, ok := s.files[snapshotManifest]
+	if !ok {
+		archive.Close()
+		return nil, fmt.Errorf("%s is not a pokedex snapshot: no %s", path, snapshotManifest)
+	}
+	data, err := readZipFile(manifest)
+	if err == nil {
+		err = json.Unmarshal(data, &s.Manifest)
+	}
+	if err != nil {
+		archive.Close()
+		return nil, fmt.Errorf("reading %s from %s: %w", snapshotManifest, path, err)
+	}
+
+	return s, nil
+}
+
+// Close закрывает архив
+func (s *Snapshot) Close() error {
+	return s.archive.Close()
+}
+
+// RoundTrip отвечает на GET запрос содержимым архива
+func (s *Snapshot) RoundTrip(req *http.Request) (*http.Response, error) {
+	url := req.URL.String()
+	resource, ok := strings.CutPrefix(url, s.Manifest.BaseURL)
+	if !ok || req.Method != http.MethodGet {
+		return nil, &OfflineError{URL: url}
+	}
+
+	file, ok := s.files[snapshotEntry(resource)]
+	if !ok {
+		return nil, &OfflineError{URL: url}
+	}
+	body, err := readZipFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": {"application/json"}},
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// snapshotEntry переводит ресурс относительно адреса API в имя файла
+// архива: pokemon/pikachu/ -> pokemon/pikachu.json,
+// location-area/?offset=0&limit=20 -> location-area?offset=0&limit=20.json
+func snapshotEntry(resource string) string {
+	path, query, _ := strings.Cut(resource, "?")
+	name := strings.Trim(path, "/")
+	if query != "" {
+		name += "?" + query
+	}
+	return name + ".json"
+}
+
+// readZipFile читает файл из архива целиком
+func readZipFile(file *zip.File) ([]byte, error) {
+	r, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return io.ReadAll(r)
+}
@@ -0,0 +1,249 @@
+package pokeapi
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/CodeHunt7/go-pokedex/internal/pokecache"
+)
+
+// Ресурсы, которые попадают в снимок целиком
+var snapshotResources = []string{"location-area", "pokemon", "pokemon-species"}
+
+// Сколько запросов снимка идут одновременно, частоту все равно держит транспорт
+const snapshotWorkers = 4
+
+// SnapshotProgress сообщает, сколько ресурсов одного вида уже скачано
+type SnapshotProgress func(resource string, done, total int)
+
+// BuildSnapshot обходит страницы списка локаций, полные списки
+// и отдельные ресурсы location-area, pokemon и pokemon-species
+// и записывает ответы в архив path. Ресурсы, которые есть в списке,
+// но отвечают 404, пропускаются. Архив появляется по пути path
+// только целиком, при ошибке или отмене ctx старый файл не трогается
+func (c *Client) BuildSnapshot(ctx context.Context, path string, progress SnapshotProgress) (SnapshotManifest, error) {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return SnapshotManifest{}, err
+	}
+	tmp, err := os.CreateTemp(dir, ".snapshot-*")
+	if err != nil {
+		return SnapshotManifest{}, err
+	}
+
+	w := &snapshotWriter{zip: zip.NewWriter(tmp), baseURL: c.baseURL}
+	err = c.crawl(ctx, w, progress)
+
+	manifest := SnapshotManifest{BaseURL: c.baseURL, CreatedAt: time.Now(), Entries: w.entries}
+	if err == nil {
+		err = w.addManifest(manifest)
+	}
+	if closeErr := w.zip.Close(); err == nil {
+		err = closeErr
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return SnapshotManifest{}, err
+	}
+
+	return manifest, nil
+}
+
+// crawl скачивает все ресурсы снимка
+func (c *Client) crawl(ctx context.Context, w *snapshotWriter, progress SnapshotProgress) error {
+	// Страницы списка локаций в том виде, в каком их листают map и mapb
+	for pageURL := c.baseURL + firstLocationPage; pageURL != ""; {
+		body, err := c.snapshotBody(ctx, pageURL)
+		if err != nil {
+			return err
+		}
+		if err := w.add(pageURL, body); err != nil {
+			return err
+		}
+
+		var page LocationAreaResponse
+		if err := json.Unmarshal(body, &page); err != nil {
+			return &DecodeError{URL: pageURL, Err: err}
+		}
+		pageURL = page.Next
+	}
+
+	// Полные списки имен и каждый ресурс из них
+	for _, resource := range snapshotResources {
+		indexURL := c.namesURL(resource)
+		body, err := c.snapshotBody(ctx, indexURL)
+		if err != nil {
+			return err
+		}
+		if err := w.add(indexURL, body); err != nil {
+			return err
+		}
+
+		var list NamedResourceList
+		if err := json.Unmarshal(body, &list); err != nil {
+			return &DecodeError{URL: indexURL, Err: err}
+		}
+		urls := make([]string, 0, len(list.Results))
+		for _, result := range list.Results {
+			urls = append(urls, c.baseURL+resource+"/"+result.Name+"/")
+		}
+
+		if err := c.crawlAll(ctx, w, resource, urls, progress); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Ответ на один запрос снимка
+type crawled struct {
+	url  string
+	body []byte
+	err  error
+}
+
+// crawlAll скачивает ресурсы по списку ссылок в несколько потоков
+// и пишет их в архив из одного потока
+func (c *Client) crawlAll(ctx context.Context, w *snapshotWriter, resource string, urls []string, progress SnapshotProgress) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	jobs := make(chan string)
+	results := make(chan crawled)
+
+	go func() {
+		defer close(jobs)
+		for _, url := range urls {
+			select {
+			case jobs <- url:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var workers sync.WaitGroup
+	for range snapshotWorkers {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for url := range jobs {
+				body, err := c.snapshotBody(ctx, url)
+				select {
+				case results <- crawled{url: url, body: body, err: err}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		workers.Wait()
+		close(results)
+	}()
+
+	var firstErr error
+	done := 0
+	for result := range results {
+		if firstErr != nil {
+			continue // дочитываем, пока потоки не остановятся
+		}
+
+		err := result.err
+		if err == nil {
+			err = w.add(result.url, result.body)
+		}
+		// Ресурс, который есть в списке, но отдельно не отдается, пропускаем
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			firstErr = err
+			cancel()
+			continue
+		}
+
+		done++
+		if progress != nil {
+			progress(resource, done, len(urls))
+		}
+	}
+
+	if firstErr == nil {
+		firstErr = ctx.Err()
+	}
+	return firstErr
+}
+
+// snapshotBody берет свежий ответ из кэша или скачивает его мимо кэша,
+// чтобы тысячи ресурсов снимка не вытеснили из кэша то, что нужно в игре
+// и не испортили его счетчики
+func (c *Client) snapshotBody(ctx context.Context, url string) ([]byte, error) {
+	if body, ok := c.cache.Peek(url); ok {
+		return body, nil
+	}
+
+	body, _, err := c.fetch(ctx, url, pokecache.Validators{})
+	if err != nil {
+		return nil, err
+	}
+	if !json.Valid(body) {
+		return nil, &DecodeError{URL: url}
+	}
+	return body, nil
+}
+
+// snapshotWriter складывает ответы в zip архив
+type snapshotWriter struct {
+	zip     *zip.Writer
+	baseURL string
+	entries int
+}
+
+// add записывает ответ по ссылке url
+func (w *snapshotWriter) add(url string, body []byte) error {
+	resource, ok := strings.CutPrefix(url, w.baseURL)
+	if !ok {
+		// Ссылка на другой адрес, в снимке ее все равно не найдут
+		return nil
+	}
+	if err := w.write(snapshotEntry(resource), body); err != nil {
+		return err
+	}
+	w.entries++
+	return nil
+}
+
+// addManifest записывает описание снимка
+func (w *snapshotWriter) addManifest(manifest SnapshotManifest) error {
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		return err
+	}
+	return w.write(snapshotManifest, data)
+}
+
+// write сжимает и записывает один файл архива
+func (w *snapshotWriter) write(name string, data []byte) error {
+	f, err := w.zip.CreateHeader(&zip.FileHeader{
+		Name:     name,
+		Method:   zip.Deflate,
+		Modified: time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	return err
+}
@@ -0,0 +1,145 @@
+package pokeapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/CodeHunt7/go-pokedex/internal/pokecache"
+)
+
+// newSnapshotServer отдает маленький PokeAPI: две страницы локаций,
+// списки имен и ресурсы из них. ghost есть в списке, но отвечает 404
+func newSnapshotServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.RequestURI() {
+		case "/location-area/?offset=0&limit=20":
+			fmt.Fprintf(w, `{"count": 2, "next": "%s/location-area/?offset=20&limit=20", "results": [{"name": "canalave-city-area"}]}`, server.URL)
+		case "/location-area/?offset=20&limit=20":
+			fmt.Fprint(w, `{"count": 2, "next": "", "results": [{"name": "eterna-city-area"}]}`)
+		case "/location-area/?offset=0&limit=100000":
+			fmt.Fprint(w, `{"results": [{"name": "canalave-city-area"}, {"name": "eterna-city-area"}]}`)
+		case "/location-area/canalave-city-area/", "/location-area/eterna-city-area/":
+			fmt.Fprint(w, `{"pokemon_encounters": [{"pokemon": {"name": "pikachu"}}]}`)
+		case "/pokemon/?offset=0&limit=100000":
+			fmt.Fprint(w, `{"results": [{"name": "pikachu"}, {"name": "ghost"}]}`)
+		case "/pokemon/pikachu/":
+			fmt.Fprint(w, `{"name": "pikachu", "base_experience": 112}`)
+		case "/pokemon-species/?offset=0&limit=100000":
+			fmt.Fprint(w, `{"results": [{"name": "pikachu"}]}`)
+		case "/pokemon-species/pikachu/":
+			fmt.Fprint(w, `{"name": "pikachu"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestBuildSnapshot(t *testing.T) {
+	server := newSnapshotServer(t)
+	path := filepath.Join(t.TempDir(), "snapshots", "snapshot.zip")
+
+	cache := pokecache.NewCache(time.Minute)
+	t.Cleanup(cache.Close)
+	client := NewClient(server.URL, server.Client(), cache)
+
+	progress := map[string]int{}
+	manifest, err := client.BuildSnapshot(context.Background(), path, func(resource string, done, total int) {
+		progress[resource] = total
+	})
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	// 2 страницы, 3 списка, 2 локации, 1 покемон, 1 вид
+	if manifest.Entries != 9 {
+		t.Errorf("expected 9 entries, got %d", manifest.Entries)
+	}
+	if progress["location-area"] != 2 || progress["pokemon"] != 2 || progress["pokemon-species"] != 1 {
+		t.Errorf("unexpected progress totals %v", progress)
+	}
+	if stats := cache.Stats(); stats.Entries != 0 || stats.Misses != 0 {
+		t.Errorf("expected snapshot to bypass the cache, got %+v", stats)
+	}
+
+	// Без сети все берется из архива
+	server.Close()
+	snapshot, err := OpenSnapshot(path)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	t.Cleanup(func() { snapshot.Close() })
+	if snapshot.Manifest.BaseURL != server.URL+"/" {
+		t.Errorf("expected base URL %s/, got %s", server.URL, snapshot.Manifest.BaseURL)
+	}
+
+	offlineCache := pokecache.NewCache(time.Minute)
+	t.Cleanup(offlineCache.Close)
+	offline := NewClient(snapshot.Manifest.BaseURL, &http.Client{Transport: snapshot}, offlineCache)
+	ctx := context.Background()
+
+	page, err := offline.ListLocationAreas(ctx, "")
+	if err != nil {
+		t.Fatalf("first page: %v", err)
+	}
+	if _, err := offline.ListLocationAreas(ctx, page.Next); err != nil {
+		t.Errorf("next page: %v", err)
+	}
+	if _, err := offline.GetLocationArea(ctx, "eterna-city-area"); err != nil {
+		t.Errorf("location area: %v", err)
+	}
+	if pokemon, err := offline.GetPokemon(ctx, "pikachu"); err != nil || pokemon.BaseExperience != 112 {
+		t.Errorf("expected pikachu from snapshot, got %+v, %v", pokemon, err)
+	}
+	if names, err := offline.ListPokemonNames(ctx); err != nil || len(names) != 2 {
+		t.Errorf("expected pokemon index from snapshot, got %v, %v", names, err)
+	}
+
+	// Чего нет в архиве - понятная ошибка, а не сетевая
+	_, err = offline.GetPokemon(ctx, "ghost")
+	var offlineErr *OfflineError
+	if !errors.As(err, &offlineErr) || errors.Is(err, ErrNetwork) {
+		t.Errorf("expected only OfflineError, got %v", err)
+	}
+}
+
+func TestBuildSnapshotFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	t.Cleanup(server.Close)
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "snapshot.zip")
+	if err := os.WriteFile(path, []byte("old snapshot"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cache := pokecache.NewCache(time.Minute)
+	t.Cleanup(cache.Close)
+	client := NewClient(server.URL, server.Client(), cache)
+
+	if _, err := client.BuildSnapshot(context.Background(), path, nil); !errors.Is(err, ErrUpstream) {
+		t.Errorf("expected ErrUpstream, got %v", err)
+	}
+
+	// Старый снимок на месте, временных файлов не осталось
+	if data, err := os.ReadFile(path); err != nil || string(data) != "old snapshot" {
+		t.Errorf("expected old snapshot to be kept, got %q, %v", data, err)
+	}
+	files, _ := os.ReadDir(dir)
+	if len(files) != 1 {
+		t.Errorf("expected only the old snapshot in %s, got %d files", dir, len(files))
+	}
+}
@@ -0,0 +1,174 @@
+package pokecache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Префикс временных файлов, которые еще не дописаны до конца
+const tmpPrefix = ".tmp-"
+
+// Структура элемента кэша в файле
+type diskEntry struct {
+	Key       string        `json:"key"`
+	CreatedAt time.Time     `json:"created_at"`
+	TTL       time.Duration `json:"ttl,omitempty"`
+	Val       []byte        `json:"val"`
+
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+}
+
+// DefaultDir возвращает папку кэша по умолчанию ($XDG_CACHE_HOME/pokedex на Linux)
+func DefaultDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "pokedex")
+}
+
+// NewDiskCache создает кэш, который хранит элементы в папке dir.
+// Элементы, сохраненные в прошлых запусках, загружаются сразу,
+// устаревшие и поврежденные файлы удаляются
+func NewDiskCache(interval time.Duration, dir string, opts ...Option) (*Cache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	c := newCache(interval, dir, opts...)
+	c.load()
+	c.start(context.Background())
+
+	return c, nil
+}
+
+// load читает элементы с диска, пропуская устаревшие и битые файлы
+func (c *Cache) load() {
+	files, err := os.ReadDir(c.dir)
+	if err != nil {
+		return
+	}
+
+	// Элементы с диска собираем, чтобы восстановить порядок вытеснения
+	type loadedEntry struct {
+		key   string
+		entry cacheEntry
+	}
+	var loaded []loadedEntry
+
+	now := c.clock.Now()
+	for _, file := range files {
+		if file.IsDir() {
+			continue
+		}
+		path := filepath.Join(c.dir, file.Name())
+
+		// Недописанный файл от прошлого запуска
+		if strings.HasPrefix(file.Name(), tmpPrefix) {
+			os.Remove(path)
+			continue
+		}
+		if filepath.Ext(file.Name()) != ".json" {
+			continue
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+
+		var entry diskEntry
+		if err := json.Unmarshal(data, &entry); err != nil || entry.Key == "" {
+			os.Remove(path)
+			continue
+		}
+
+		// Учитываем интервал очистки между запусками
+		restored := cacheEntry{
+			createdAt: entry.CreatedAt,
+			val:       entry.Val,
+			ttl:       entry.TTL,
+			validators: Validators{
+				ETag:         entry.ETag,
+				LastModified: entry.LastModified,
+			},
+		}
+		if c.expired(restored, now) {
+			os.Remove(path)
+			continue
+		}
+
+		loaded = append(loaded, loadedEntry{key: entry.Key, entry: restored})
+	}
+
+	// Файлы читаются в порядке имен, а не использования: кладем элементы
+	// от старых к новым, чтобы первыми вытеснялись самые старые
+	sort.Slice(loaded, func(i, j int) bool {
+		return loaded[i].entry.createdAt.Before(loaded[j].entry.createdAt)
+	})
+	for _, l := range loaded {
+		c.set(l.key, l.entry)
+	}
+
+	c.evict()
+}
+
+// writeEntry сохраняет элемент на диск через временный файл,
+// чтобы при падении не осталось наполовину записанного элемента
+func (c *Cache) writeEntry(key string, entry cacheEntry) {
+	if c.dir == "" {
+		return
+	}
+
+	data, err := json.Marshal(diskEntry{
+		Key:       key,
+		CreatedAt: entry.createdAt,
+		TTL:       entry.ttl,
+		Val:       entry.val,
+
+		ETag:         entry.validators.ETag,
+		LastModified: entry.validators.LastModified,
+	})
+	if err != nil {
+		return
+	}
+
+	tmp, err := os.CreateTemp(c.dir, tmpPrefix+"*")
+	if err != nil {
+		return
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return
+	}
+
+	if err := os.Rename(tmp.Name(), c.entryPath(key)); err != nil {
+		os.Remove(tmp.Name())
+	}
+}
+
+// removeEntry удаляет файл элемента с диска
+func (c *Cache) removeEntry(key string) {
+	if c.dir == "" {
+		return
+	}
+	os.Remove(c.entryPath(key))
+}
+
+// entryPath возвращает путь к файлу элемента
+func (c *Cache) entryPath(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".json")
+}
@@ -0,0 +1,171 @@
+package pokecache
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDiskCacheReload(t *testing.T) {
+	const interval = 5 * time.Second
+	dir := t.TempDir()
+
+	cache, err := NewDiskCache(interval, dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer cache.Close()
+	cache.Add("https://example.com", []byte("testdata"))
+
+	// Новый кэш в той же папке должен увидеть элемент
+	reloaded, err := NewDiskCache(interval, dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer reloaded.Close()
+	val, ok := reloaded.Get("https://example.com")
+	if !ok {
+		t.Errorf("expected to find key after reload")
+		return
+	}
+	if string(val) != "testdata" {
+		t.Errorf("expected to find value after reload")
+	}
+}
+
+func TestDiskCacheSkipsExpired(t *testing.T) {
+	const interval = 5 * time.Second
+	dir := t.TempDir()
+
+	data, err := json.Marshal(diskEntry{
+		Key:       "https://example.com",
+		CreatedAt: time.Now().Add(-2 * interval),
+		Val:       []byte("testdata"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := newCache(interval, dir).entryPath("https://example.com")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cache, err := NewDiskCache(interval, dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer cache.Close()
+	if _, ok := cache.Get("https://example.com"); ok {
+		t.Errorf("expected expired key to be skipped")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("expected expired file to be removed")
+	}
+}
+
+func TestDiskCacheCorruptedFiles(t *testing.T) {
+	const interval = 5 * time.Second
+	dir := t.TempDir()
+
+	cache, err := NewDiskCache(interval, dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer cache.Close()
+	cache.Add("https://example.com", []byte("testdata"))
+
+	// Битый файл и недописанный временный файл
+	corrupted := filepath.Join(dir, "corrupted.json")
+	if err := os.WriteFile(corrupted, []byte(`{"key": "https://exa`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	partial := filepath.Join(dir, tmpPrefix+"123")
+	if err := os.WriteFile(partial, []byte(`{"key"`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded, err := NewDiskCache(interval, dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer reloaded.Close()
+	if _, ok := reloaded.Get("https://example.com"); !ok {
+		t.Errorf("expected to find valid key next to corrupted files")
+	}
+	for _, path := range []string{corrupted, partial} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("expected %s to be removed", filepath.Base(path))
+		}
+	}
+}
+
+func TestDiskCacheValidators(t *testing.T) {
+	const interval = 5 * time.Second
+	dir := t.TempDir()
+	clock := NewFakeClock(time.Now())
+
+	cache, err := NewDiskCache(interval, dir, WithClock(clock), WithStaleWhileRevalidate(time.Hour))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer cache.Close()
+	validators := Validators{ETag: `W/"abc"`, LastModified: "Mon, 02 Jan 2006 15:04:05 GMT"}
+	cache.AddWithValidators("https://example.com", []byte("testdata"), 0, validators)
+
+	// Touch сохраняет новое время на диск
+	clock.Advance(time.Minute)
+	cache.Touch("https://example.com")
+
+	reloaded, err := NewDiskCache(interval, dir, WithClock(clock), WithStaleWhileRevalidate(time.Hour))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer reloaded.Close()
+	if actual, ok := reloaded.Validators("https://example.com"); !ok || actual != validators {
+		t.Errorf("expected validators %+v after reload, got %+v ok=%v", validators, actual, ok)
+	}
+	if _, ok := reloaded.Get("https://example.com"); !ok {
+		t.Errorf("expected touched key to be fresh after reload")
+	}
+}
+
+func TestDiskCacheReloadOrder(t *testing.T) {
+	const interval = time.Hour
+	dir := t.TempDir()
+	now := time.Now()
+
+	// Имена файлов - хэши ключей, порядок чтения с диска от времени не зависит
+	keys := make([]string, 20)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("https://example.com/%d", i)
+	}
+	for i, key := range keys {
+		data, err := json.Marshal(diskEntry{
+			Key:       key,
+			CreatedAt: now.Add(time.Duration(i-len(keys)) * time.Minute),
+			Val:       []byte("testdata"),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(newCache(interval, dir).entryPath(key), data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// При перезапуске с лимитом остаются самые новые элементы
+	cache, err := NewDiskCache(interval, dir, WithMaxEntries(2))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer cache.Close()
+	for i, key := range keys {
+		_, ok := cache.Get(key)
+		if expected := i >= len(keys)-2; ok != expected {
+			t.Errorf("%s: expected present=%v, got %v", key, expected, ok)
+		}
+	}
+}
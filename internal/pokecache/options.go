@@ -0,0 +1,38 @@
+package pokecache
+
+import "time"
+
+// Option настраивает кэш при создании
+type Option func(*Cache)
+
+// WithMaxEntries ограничивает количество элементов в кэше.
+// При превышении вытесняются давно неиспользуемые элементы, 0 - без ограничения
+func WithMaxEntries(n int) Option {
+	return func(c *Cache) {
+		c.maxEntries = n
+	}
+}
+
+// WithMaxBytes ограничивает суммарный размер ключей и значений в байтах.
+// При превышении вытесняются давно неиспользуемые элементы, 0 - без ограничения
+func WithMaxBytes(n int) Option {
+	return func(c *Cache) {
+		c.maxBytes = n
+	}
+}
+
+// WithClock подменяет часы кэша, например на FakeClock в тестах
+func WithClock(clock Clock) Option {
+	return func(c *Cache) {
+		c.clock = clock
+	}
+}
+
+// WithStaleWhileRevalidate оставляет элементы в кэше еще на maxStale после
+// истечения TTL. Get их не отдает, а GetStale отдает с пометкой, что пора
+// обновить
+func WithStaleWhileRevalidate(maxStale time.Duration) Option {
+	return func(c *Cache) {
+		c.maxStale = maxStale
+	}
+}
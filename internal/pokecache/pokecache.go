@@ -1,6 +1,8 @@
 ﻿package pokecache
 
 import (
+	"container/list"
+	"context"
 	"sync"
 	"time"
 )
@@ -8,40 +10,138 @@ import (
 type Cache struct {
 	cache map[string]cacheEntry
 	mu    *sync.Mutex
+	dir   string // папка для записи на диск, пустая для кэша в памяти
+
+	interval time.Duration // сколько живут элементы без своего TTL
+	maxStale time.Duration // сколько еще хранить устаревшие элементы
+	clock    Clock
+
+	// Вытеснение давно неиспользуемых элементов
+	order      *list.List // ключи, от недавно использованных к давно
+	size       int        // сколько байт занимают ключи и значения
+	maxEntries int
+	maxBytes   int
+
+	stats Stats
+
+	// Остановка фоновой очистки
+	cancel context.CancelFunc
+	done   chan struct{}
 }
 
 type cacheEntry struct {
-	createdAt time.Time
-	val       []byte
+	createdAt  time.Time
+	val        []byte
+	ttl        time.Duration // 0 означает интервал кэша
+	validators Validators
+	elem       *list.Element // место ключа в order
+}
+
+// Validators - заголовки ответа, по которым сервер может подтвердить,
+// что сохраненный ответ не изменился
+type Validators struct {
+	ETag         string
+	LastModified string
+}
+
+// IsZero сообщает, что валидаторов нет и условный запрос сделать нельзя
+func (v Validators) IsZero() bool {
+	return v.ETag == "" && v.LastModified == ""
 }
 
 // NewCache создает новый экземпляр кэша с заданным интервалом очистки
-func NewCache(interval time.Duration) Cache {
-	c := Cache{
-		cache: make(map[string]cacheEntry),
-		mu:    &sync.Mutex{},
-	}
+func NewCache(interval time.Duration, opts ...Option) *Cache {
+	return NewCacheWithContext(context.Background(), interval, opts...)
+}
+
+// NewCacheWithContext создает кэш, фоновая очистка которого
+// останавливается при отмене ctx или вызове Close
+func NewCacheWithContext(ctx context.Context, interval time.Duration, opts ...Option) *Cache {
+	c := newCache(interval, "", opts...)
+	c.start(ctx)
 
-	go c.reapLoop(interval)
-	
 	return c
 }
 
+// newCache создает пустой кэш без запуска очистки
+func newCache(interval time.Duration, dir string, opts ...Option) *Cache {
+	c := &Cache{
+		cache:    make(map[string]cacheEntry),
+		mu:       &sync.Mutex{},
+		dir:      dir,
+		interval: interval,
+		clock:    realClock{},
+		order:    list.New(),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// start запускает фоновую очистку
+func (c *Cache) start(ctx context.Context) {
+	ctx, c.cancel = context.WithCancel(ctx)
+	c.done = make(chan struct{})
+
+	// Таймер заводим сразу, чтобы первый тик считался от создания кэша
+	go c.reapLoop(ctx, c.clock.NewTicker(c.interval))
+}
+
+// Close останавливает фоновую очистку и ждет ее завершения.
+// Кэшем можно пользоваться и после Close, но устаревшие элементы
+// больше не удаляются
+func (c *Cache) Close() {
+	c.cancel()
+	<-c.done
+}
+
 // Add добавляет новый элемент в кэш
 func (c *Cache) Add(key string, val []byte) {
+	c.AddWithTTL(key, val, 0)
+}
+
+// AddWithTTL добавляет элемент, который считается свежим в течение ttl.
+// Если ttl равен 0, используется интервал кэша
+func (c *Cache) AddWithTTL(key string, val []byte, ttl time.Duration) {
+	c.AddWithValidators(key, val, ttl, Validators{})
+}
+
+// AddWithValidators добавляет элемент вместе с валидаторами ответа,
+// чтобы после истечения ttl его можно было проверить условным запросом
+func (c *Cache) AddWithValidators(key string, val []byte, ttl time.Duration, validators Validators) {
 	// Блокируем мьютекс на время записи
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	c.cache[key] = cacheEntry{
-		val:       val,
-		createdAt: time.Now(),
+	entry := cacheEntry{
+		val:        val,
+		createdAt:  c.clock.Now(),
+		ttl:        ttl,
+		validators: validators,
 	}
+	c.set(key, entry)
+	c.writeEntry(key, entry)
+	c.evict()
 }
 
-// Get получает элемент из кэша по ключу
-func (c *Cache) Get(key string) ([]byte, bool) {
-	// Блокируем мьютекс на время чтения
+// Validators возвращает валидаторы элемента, даже устаревшего
+// или вышедшего за окно WithStaleWhileRevalidate, пока его не удалила
+// очистка или вытеснение. Счетчики попаданий и порядок вытеснения не меняются
+func (c *Cache) Validators(key string) (Validators, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, exists := c.cache[key]
+	if !exists {
+		return Validators{}, false
+	}
+	return entry.validators, true
+}
+
+// Touch снова делает элемент свежим, не меняя значения, и возвращает его.
+// Используется, когда сервер подтвердил, что ответ не изменился
+func (c *Cache) Touch(key string) ([]byte, bool) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -50,26 +150,179 @@ func (c *Cache) Get(key string) ([]byte, bool) {
 		return nil, false
 	}
 
+	entry.createdAt = c.clock.Now()
+	c.set(key, entry)
+	c.writeEntry(key, entry)
+
 	return entry.val, true
 }
 
+// Get получает свежий элемент из кэша по ключу
+func (c *Cache) Get(key string) ([]byte, bool) {
+	// Блокируем мьютекс на время чтения
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	val, fresh, ok := c.lookup(key)
+	if !ok || !fresh {
+		c.stats.Misses++
+		return nil, false
+	}
+
+	c.stats.Hits++
+	return val, true
+}
+
+// Peek получает свежий элемент, не трогая счетчики и порядок вытеснения.
+// Нужен для повторных проверок, когда сам поиск уже посчитан через Get или GetStale
+func (c *Cache) Peek(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, exists := c.cache[key]
+	if !exists || !c.fresh(entry, c.clock.Now()) {
+		return nil, false
+	}
+	return entry.val, true
+}
+
+// GetStale получает элемент, даже если он уже не свежий.
+// fresh сообщает, не истек ли TTL элемента. Устаревшие элементы
+// хранятся только в режиме WithStaleWhileRevalidate
+func (c *Cache) GetStale(key string) (val []byte, fresh bool, ok bool) {
+	// Блокируем мьютекс на время чтения
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	val, fresh, ok = c.lookup(key)
+	if !ok {
+		c.stats.Misses++
+		return nil, false, false
+	}
+
+	c.stats.Hits++
+	return val, fresh, true
+}
+
+// lookup ищет элемент и отмечает его как недавно использованный.
+// Вызывается под мьютексом
+func (c *Cache) lookup(key string) (val []byte, fresh bool, ok bool) {
+	entry, exists := c.cache[key]
+	if !exists {
+		return nil, false, false
+	}
+
+	// Элемент устарел, но очистка до него еще не дошла. Не удаляем его,
+	// чтобы load мог проверить ответ условным запросом по валидаторам
+	now := c.clock.Now()
+	if c.expired(entry, now) {
+		return nil, false, false
+	}
+
+	// Элемент только что использовали, двигаем его в начало
+	c.order.MoveToFront(entry.elem)
+
+	return entry.val, c.fresh(entry, now), true
+}
+
+// Len возвращает количество элементов в кэше
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.cache)
+}
+
+// ttl возвращает время жизни элемента
+func (c *Cache) ttl(entry cacheEntry) time.Duration {
+	if entry.ttl > 0 {
+		return entry.ttl
+	}
+	return c.interval
+}
+
+// fresh проверяет, что TTL элемента еще не истек
+func (c *Cache) fresh(entry cacheEntry, now time.Time) bool {
+	return now.Sub(entry.createdAt) <= c.ttl(entry)
+}
+
+// expired проверяет, что элемент пора удалить: истек TTL
+// и время, которое разрешено хранить устаревший элемент
+func (c *Cache) expired(entry cacheEntry, now time.Time) bool {
+	return now.Sub(entry.createdAt) > c.ttl(entry)+c.maxStale
+}
+
+// set кладет элемент в кэш и отмечает его как недавно использованный.
+// Вызывается под мьютексом
+func (c *Cache) set(key string, entry cacheEntry) {
+	if old, exists := c.cache[key]; exists {
+		c.size -= len(key) + len(old.val)
+		entry.elem = old.elem
+		c.order.MoveToFront(entry.elem)
+	} else {
+		entry.elem = c.order.PushFront(key)
+	}
+
+	c.cache[key] = entry
+	c.size += len(key) + len(entry.val)
+}
+
+// delete убирает элемент из кэша и с диска. Вызывается под мьютексом
+func (c *Cache) delete(key string) {
+	entry, exists := c.cache[key]
+	if !exists {
+		return
+	}
+
+	c.order.Remove(entry.elem)
+	c.size -= len(key) + len(entry.val)
+	delete(c.cache, key)
+	c.removeEntry(key)
+}
+
+// evict вытесняет давно неиспользуемые элементы, пока кэш не влезет
+// в ограничения. Вызывается под мьютексом
+func (c *Cache) evict() {
+	for c.order.Len() > 0 {
+		overEntries := c.maxEntries > 0 && len(c.cache) > c.maxEntries
+		overBytes := c.maxBytes > 0 && c.size > c.maxBytes
+		if !overEntries && !overBytes {
+			return
+		}
+
+		oldest := c.order.Back()
+		c.delete(oldest.Value.(string))
+		c.stats.Evictions++
+	}
+}
+
 // reapLoop периодически очищает устаревшие элементы из кэша
-func (c *Cache) reapLoop(interval time.Duration) {
-	// Делаем таймер
-	ticker := time.NewTicker(interval)
+func (c *Cache) reapLoop(ctx context.Context, ticker Ticker) {
+	defer close(c.done)
 	defer ticker.Stop()
 
-	// Цикл ждет тика
-	for t:= range ticker.C {
-		c.mu.Lock()
-
-		for key, entry := range c.cache {
-			// Если элемент старше интервала, удаляем
-			if t.Sub(entry.createdAt) > interval {
-				delete(c.cache, key)
-			}
+	// Цикл ждет тика или остановки. Тик мог залежаться в канале,
+	// поэтому сверяемся с текущим временем, а не со временем тика
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+			c.reap(c.clock.Now())
 		}
+	}
+}
+
+// reap удаляет устаревшие элементы
+func (c *Cache) reap(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
-		c.mu.Unlock()
-	}	
+	for key, entry := range c.cache {
+		// Если элемент устарел, удаляем
+		if c.expired(entry, now) {
+			c.delete(key)
+			c.stats.Expirations++
+		}
+	}
 }
\ No newline at end of file
@@ -1,7 +1,9 @@
 ﻿package pokecache
 
 import (
+	"context"
 	"fmt"
+	"runtime"
 	"testing"
 	"time"
 )
@@ -25,6 +27,7 @@ func TestAddGet(t *testing.T) {
 	for i, c := range cases {
 		t.Run(fmt.Sprintf("Test case %v", i), func(t *testing.T) {
 			cache := NewCache(interval)
+			defer cache.Close()
 			cache.Add(c.key, c.val)
 			val, ok := cache.Get(c.key)
 			if !ok {
@@ -42,20 +45,239 @@ func TestAddGet(t *testing.T) {
 func TestReapLoop(t *testing.T) {
 	const baseTime = 5 * time.Millisecond
 	const waitTime = baseTime + 5*time.Millisecond
-	cache := NewCache(baseTime)
+	clock := NewFakeClock(time.Now())
+	cache := newCache(baseTime, "", WithClock(clock))
 	cache.Add("https://example.com", []byte("testdata"))
 
-	_, ok := cache.Get("https://example.com")
-	if !ok {
+	// Тики отдаем сами через канал без буфера: второй тик примется,
+	// только когда очистка после первого уже закончилась
+	ticker := &manualTicker{c: make(chan time.Time)}
+	ctx, cancel := context.WithCancel(context.Background())
+	cache.done = make(chan struct{})
+	go cache.reapLoop(ctx, ticker)
+
+	clock.Advance(waitTime)
+	ticker.c <- clock.Now()
+	ticker.c <- clock.Now()
+	cancel()
+	<-cache.done
+
+	if cache.Len() != 0 {
+		t.Errorf("expected reapLoop to remove the key")
+	}
+	if expirations := cache.Stats().Expirations; expirations != 1 {
+		t.Errorf("expected 1 expiration, got %d", expirations)
+	}
+}
+
+// manualTicker - таймер, тики которого отправляет сам тест
+type manualTicker struct {
+	c chan time.Time
+}
+
+func (t *manualTicker) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *manualTicker) Stop() {}
+
+func TestReap(t *testing.T) {
+	const interval = 5 * time.Second
+	clock := NewFakeClock(time.Now())
+	cache := NewCache(interval, WithClock(clock))
+	defer cache.Close()
+
+	cache.Add("https://example.com/old", []byte("old"))
+	clock.Advance(3 * time.Second)
+	cache.Add("https://example.com/new", []byte("new"))
+	clock.Advance(3 * time.Second)
+
+	// Проверяем очистку напрямую, без ожидания тика
+	cache.reap(clock.Now())
+
+	if cache.Len() != 1 {
+		t.Errorf("expected 1 entry after reap, got %d", cache.Len())
+	}
+	if _, ok := cache.Get("https://example.com/new"); !ok {
+		t.Errorf("expected fresh key to survive reap")
+	}
+}
+
+func TestEvictMaxEntries(t *testing.T) {
+	const interval = 5 * time.Second
+	cache := NewCache(interval, WithMaxEntries(2))
+	defer cache.Close()
+	cache.Add("https://example.com/1", []byte("one"))
+	cache.Add("https://example.com/2", []byte("two"))
+
+	// Get освежает первый ключ, поэтому вытеснен должен быть второй
+	if _, ok := cache.Get("https://example.com/1"); !ok {
 		t.Errorf("expected to find key")
 		return
 	}
+	cache.Add("https://example.com/3", []byte("three"))
+
+	if _, ok := cache.Get("https://example.com/2"); ok {
+		t.Errorf("expected least recently used key to be evicted")
+	}
+	for _, key := range []string{"https://example.com/1", "https://example.com/3"} {
+		if _, ok := cache.Get(key); !ok {
+			t.Errorf("expected to find %s", key)
+		}
+	}
+	if cache.Len() != 2 {
+		t.Errorf("expected 2 entries, got %d", cache.Len())
+	}
+}
 
-	time.Sleep(waitTime)
+func TestEvictMaxBytes(t *testing.T) {
+	const interval = 5 * time.Second
+	// Ключ и значение по 5 байт, в бюджет влезают два элемента
+	cache := NewCache(interval, WithMaxBytes(20))
+	defer cache.Close()
+	cache.Add("key-1", []byte("val-1"))
+	cache.Add("key-2", []byte("val-2"))
+	cache.Add("key-3", []byte("val-3"))
 
-	_, ok = cache.Get("https://example.com")
-	if ok {
-		t.Errorf("expected to not find key")
-		return
+	if _, ok := cache.Get("key-1"); ok {
+		t.Errorf("expected oldest key to be evicted")
+	}
+
+	// Перезапись большим значением вытесняет все остальное
+	cache.Add("key-3", []byte("a-much-longer-value"))
+	if _, ok := cache.Get("key-2"); ok {
+		t.Errorf("expected key-2 to be evicted")
+	}
+	if cache.Len() != 0 {
+		t.Errorf("expected oversized entry to be evicted too, got %d entries", cache.Len())
+	}
+}
+
+func TestCloseStopsReaper(t *testing.T) {
+	before := runtime.NumGoroutine()
+
+	caches := []*Cache{
+		NewCache(time.Millisecond),
+		NewCache(time.Millisecond, WithMaxEntries(10)),
+	}
+	for _, cache := range caches {
+		cache.Close()
+	}
+
+	// Отмена контекста тоже останавливает очистку
+	ctx, cancel := context.WithCancel(context.Background())
+	cache := NewCacheWithContext(ctx, time.Millisecond)
+	cancel()
+	<-cache.done
+
+	// Горутина может еще выходить после закрытия done
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		runtime.Gosched()
 	}
-}
\ No newline at end of file
+	if after := runtime.NumGoroutine(); after > before {
+		t.Errorf("expected no leaked goroutines, had %d before and %d after", before, after)
+	}
+}
+
+func TestAddWithTTL(t *testing.T) {
+	const interval = 5 * time.Second
+	clock := NewFakeClock(time.Now())
+	cache := NewCache(interval, WithClock(clock))
+	defer cache.Close()
+
+	cache.Add("https://example.com/short", []byte("short"))
+	cache.AddWithTTL("https://example.com/long", []byte("long"), time.Hour)
+	clock.Advance(time.Minute)
+
+	if _, ok := cache.Get("https://example.com/short"); ok {
+		t.Errorf("expected key with default interval to expire")
+	}
+	if _, ok := cache.Get("https://example.com/long"); !ok {
+		t.Errorf("expected key with long TTL to survive")
+	}
+}
+
+func TestStaleWhileRevalidate(t *testing.T) {
+	const interval = 5 * time.Second
+	clock := NewFakeClock(time.Now())
+	cache := NewCache(interval, WithClock(clock), WithStaleWhileRevalidate(time.Minute))
+	defer cache.Close()
+	cache.Add("https://example.com", []byte("testdata"))
+
+	clock.Advance(30 * time.Second)
+
+	// Get отдает только свежие элементы
+	if _, ok := cache.Get("https://example.com"); ok {
+		t.Errorf("expected Get to skip stale key")
+	}
+	val, fresh, ok := cache.GetStale("https://example.com")
+	if !ok || fresh || string(val) != "testdata" {
+		t.Errorf("expected stale value, got %q fresh=%v ok=%v", val, fresh, ok)
+	}
+
+	// После окна устаревания элемент больше не отдается
+	clock.Advance(time.Minute)
+	if _, _, ok := cache.GetStale("https://example.com"); ok {
+		t.Errorf("expected key to be removed after max stale window")
+	}
+}
+
+func TestRevalidateExpired(t *testing.T) {
+	const interval = time.Hour
+	clock := NewFakeClock(time.Now())
+	cache := NewCache(interval, WithClock(clock), WithStaleWhileRevalidate(0))
+	defer cache.Close()
+
+	validators := Validators{ETag: `"abc"`}
+	cache.AddWithValidators("https://example.com", []byte("testdata"), time.Second, validators)
+	clock.Advance(time.Minute)
+
+	// Без окна устаревания элемент сразу не отдается,
+	// но до очистки его еще можно проверить условным запросом
+	if _, _, ok := cache.GetStale("https://example.com"); ok {
+		t.Errorf("expected GetStale to skip expired key")
+	}
+	if actual, ok := cache.Validators("https://example.com"); !ok || actual != validators {
+		t.Errorf("expected validators %+v, got %+v ok=%v", validators, actual, ok)
+	}
+	if val, ok := cache.Touch("https://example.com"); !ok || string(val) != "testdata" {
+		t.Errorf("expected touched value, got %q ok=%v", val, ok)
+	}
+	if val, ok := cache.Get("https://example.com"); !ok || string(val) != "testdata" {
+		t.Errorf("expected fresh value after touch, got %q ok=%v", val, ok)
+	}
+}
+
+func TestTouch(t *testing.T) {
+	const interval = 5 * time.Second
+	clock := NewFakeClock(time.Now())
+	cache := NewCache(interval, WithClock(clock), WithStaleWhileRevalidate(time.Minute))
+	defer cache.Close()
+
+	validators := Validators{ETag: `"abc"`, LastModified: "Mon, 02 Jan 2006 15:04:05 GMT"}
+	cache.AddWithValidators("https://example.com", []byte("testdata"), 0, validators)
+	clock.Advance(30 * time.Second)
+
+	// Валидаторы доступны и у устаревшего элемента
+	actual, ok := cache.Validators("https://example.com")
+	if !ok || actual != validators {
+		t.Errorf("expected validators %+v, got %+v ok=%v", validators, actual, ok)
+	}
+
+	// После Touch элемент снова свежий и с тем же значением
+	val, ok := cache.Touch("https://example.com")
+	if !ok || string(val) != "testdata" {
+		t.Errorf("expected touched value, got %q ok=%v", val, ok)
+	}
+	if val, ok := cache.Get("https://example.com"); !ok || string(val) != "testdata" {
+		t.Errorf("expected fresh value after touch, got %q ok=%v", val, ok)
+	}
+
+	if _, ok := cache.Touch("https://example.com/missing"); ok {
+		t.Errorf("expected touch of missing key to fail")
+	}
+	if _, ok := cache.Validators("https://example.com/missing"); ok {
+		t.Errorf("expected no validators for missing key")
+	}
+}
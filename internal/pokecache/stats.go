@@ -0,0 +1,89 @@
+package pokecache
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// Stats - счетчики работы кэша
+type Stats struct {
+	Entries     int
+	Bytes       int
+	Hits        int
+	Misses      int
+	Evictions   int // вытеснены из-за ограничений размера
+	Expirations int // удалены по истечении срока
+}
+
+// EntryInfo описывает элемент кэша без его значения
+type EntryInfo struct {
+	Key       string
+	Size      int
+	CreatedAt time.Time
+	ExpiresAt time.Time // когда элемент перестанет быть свежим
+	Fresh     bool
+}
+
+// Stats возвращает текущие счетчики кэша. Истекшие элементы, которые
+// еще ждут очистки, не считаются, как и в Entries
+func (c *Cache) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := c.stats
+	stats.Entries = len(c.cache)
+	stats.Bytes = c.size
+
+	now := c.clock.Now()
+	for key, entry := range c.cache {
+		if c.expired(entry, now) {
+			stats.Entries--
+			stats.Bytes -= len(key) + len(entry.val)
+		}
+	}
+	return stats
+}
+
+// Entries возвращает элементы, ключи которых начинаются с prefix,
+// в порядке сортировки ключей
+func (c *Cache) Entries(prefix string) []EntryInfo {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.clock.Now()
+	entries := []EntryInfo{}
+	for key, entry := range c.cache {
+		if !strings.HasPrefix(key, prefix) || c.expired(entry, now) {
+			continue
+		}
+		entries = append(entries, EntryInfo{
+			Key:       key,
+			Size:      len(entry.val),
+			CreatedAt: entry.createdAt,
+			ExpiresAt: entry.createdAt.Add(c.ttl(entry)),
+			Fresh:     c.fresh(entry, now),
+		})
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Key < entries[j].Key
+	})
+	return entries
+}
+
+// Purge удаляет элементы, ключи которых начинаются с prefix,
+// и возвращает сколько удалено. Пустой prefix очищает весь кэш
+func (c *Cache) Purge(prefix string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	removed := 0
+	for key := range c.cache {
+		if strings.HasPrefix(key, prefix) {
+			c.delete(key)
+			removed++
+		}
+	}
+	return removed
+}
@@ -0,0 +1,53 @@
+package pokecache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStatsEntriesPurge(t *testing.T) {
+	const interval = 5 * time.Second
+	cache := NewCache(interval, WithMaxEntries(2))
+	defer cache.Close()
+
+	cache.Add("https://example.com/pokemon/1", []byte("one"))
+	cache.Add("https://example.com/pokemon/2", []byte("two"))
+	cache.Add("https://example.com/location-area/1", []byte("three"))
+	cache.Get("https://example.com/pokemon/2")
+	cache.Get("https://example.com/pokemon/1")
+
+	stats := cache.Stats()
+	if stats.Entries != 2 || stats.Hits != 1 || stats.Misses != 1 || stats.Evictions != 1 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+
+	entries := cache.Entries("https://example.com/pokemon/")
+	if len(entries) != 1 || entries[0].Key != "https://example.com/pokemon/2" || entries[0].Size != 3 {
+		t.Errorf("unexpected entries: %+v", entries)
+	}
+
+	if removed := cache.Purge("https://example.com/location-area/"); removed != 1 {
+		t.Errorf("expected 1 purged entry, got %d", removed)
+	}
+	if cache.Len() != 1 {
+		t.Errorf("expected 1 entry after purge, got %d", cache.Len())
+	}
+}
+
+func TestStatsSkipExpired(t *testing.T) {
+	const interval = time.Hour
+	clock := NewFakeClock(time.Now())
+	cache := NewCache(interval, WithClock(clock))
+	defer cache.Close()
+
+	cache.AddWithTTL("https://example.com/pokemon/1", []byte("one"), time.Second)
+	cache.Add("https://example.com/pokemon/2", []byte("two"))
+	clock.Advance(time.Minute)
+
+	// Истекший элемент еще ждет очистки, но ни stats, ни ls его не показывают
+	stats := cache.Stats()
+	entries := cache.Entries("")
+	if stats.Entries != 1 || len(entries) != 1 || stats.Bytes != len(entries[0].Key)+entries[0].Size {
+		t.Errorf("expected stats to match entries, got %+v and %+v", stats, entries)
+	}
+}
@@ -0,0 +1,271 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Насколько глубоко макросы могут вызывать друг друга
+const maxMacroDepth = 10
+
+// Разделитель команд в теле макроса
+const macroSeparator = ";"
+
+func commandAlias(ctx context.Context, cfg *Config, parameters []string) error {
+	switch {
+	case len(parameters) == 0:
+		return render(cfg, aliasesView{Aliases: cfg.settings.Aliases})
+	case parameters[0] == "-d":
+		if len(parameters) != 2 {
+			return errors.New("usage: alias -d <name>")
+		}
+		if _, exists := cfg.settings.Aliases[parameters[1]]; !exists {
+			return fmt.Errorf("alias %q does not exist", parameters[1])
+		}
+		delete(cfg.settings.Aliases, parameters[1])
+		if err := persistSettings(cfg); err != nil {
+			return err
+		}
+		return render(cfg, messageView{fmt.Sprintf("Deleted alias %s", parameters[1])})
+	case len(parameters) == 1:
+		expansion, exists := cfg.settings.Aliases[parameters[0]]
+		if !exists {
+			return fmt.Errorf("alias %q does not exist", parameters[0])
+		}
+		return render(cfg, aliasesView{Aliases: map[string]string{parameters[0]: expansion}})
+	}
+
+	name := parameters[0]
+	if err := checkUserCommandName(cfg, name); err != nil {
+		return err
+	}
+	if _, isMacro := cfg.settings.Macros[name]; isMacro {
+		return fmt.Errorf("%q is already a macro", name)
+	}
+
+	if cfg.settings.Aliases == nil {
+		cfg.settings.Aliases = make(map[string]string)
+	}
+	cfg.settings.Aliases[name] = strings.Join(parameters[1:], " ")
+	if err := persistSettings(cfg); err != nil {
+		return err
+	}
+	return render(cfg, messageView{fmt.Sprintf("Alias %s = %s", name, cfg.settings.Aliases[name])})
+}
+
+func commandMacro(ctx context.Context, cfg *Config, parameters []string) error {
+	switch {
+	case len(parameters) == 0:
+		return render(cfg, macrosView{Macros: cfg.settings.Macros})
+	case parameters[0] == "-d":
+		if len(parameters) != 2 {
+			return errors.New("usage: macro -d <name>")
+		}
+		if _, exists := cfg.settings.Macros[parameters[1]]; !exists {
+			return fmt.Errorf("macro %q does not exist", parameters[1])
+		}
+		delete(cfg.settings.Macros, parameters[1])
+		if err := persistSettings(cfg); err != nil {
+			return err
+		}
+		return render(cfg, messageView{fmt.Sprintf("Deleted macro %s", parameters[1])})
+	case len(parameters) == 1:
+		body, exists := cfg.settings.Macros[parameters[0]]
+		if !exists {
+			return fmt.Errorf("macro %q does not exist", parameters[0])
+		}
+		return render(cfg, macrosView{Macros: map[string][]string{parameters[0]: body}})
+	}
+
+	name := parameters[0]
+	if err := checkUserCommandName(cfg, name); err != nil {
+		return err
+	}
+	if _, isAlias := cfg.settings.Aliases[name]; isAlias {
+		return fmt.Errorf("%q is already an alias", name)
+	}
+
+	// Тело макроса - команды через ';'
+	body := []string{}
+	for _, command := range strings.Split(strings.Join(parameters[1:], " "), macroSeparator) {
+		if command = strings.TrimSpace(command); command != "" {
+			body = append(body, command)
+		}
+	}
+	if len(body) == 0 {
+		return errors.New("macro body is empty")
+	}
+
+	if cfg.settings.Macros == nil {
+		cfg.settings.Macros = make(map[string][]string)
+	}
+	cfg.settings.Macros[name] = body
+	if err := persistSettings(cfg); err != nil {
+		return err
+	}
+	return render(cfg, messageView{fmt.Sprintf("Macro %s = %s", name, strings.Join(body, "; "))})
+}
+
+// checkUserCommandName не дает псевдонимам и макросам закрыть встроенные команды
+func checkUserCommandName(cfg *Config, name string) error {
+	if _, exists := cfg.registry.lookup(name); exists {
+		return fmt.Errorf("%q is a built-in command", name)
+	}
+	if strings.HasPrefix(name, "-") || strings.HasPrefix(name, "$") {
+		return fmt.Errorf("invalid name %q", name)
+	}
+	return nil
+}
+
+// persistSettings сохраняет конфиг-файл, если он есть
+func persistSettings(cfg *Config) error {
+	if cfg.settingsPath == "" {
+		return nil
+	}
+	return saveSettings(cfg.settingsPath, cfg.settings)
+}
+
+// expandAlias подставляет пользовательский псевдоним вместо первого слова.
+// Псевдонимы не раскрываются рекурсивно. Пустой псевдоним из конфиг-файла
+// не раскрывается, чтобы не остаться без команды
+func expandAlias(cfg *Config, userInput []string) []string {
+	expansion := strings.Fields(cfg.settings.Aliases[strings.ToLower(userInput[0])])
+	if len(expansion) == 0 {
+		return userInput
+	}
+	return append(expansion, userInput[1:]...)
+}
+
+// runMacro выполняет команды макроса по очереди, подставляя аргументы
+// вместо $1..$9 и $@. Останавливается на первой ошибке.
+// Ошибки команд макроса выводит dispatch, здесь - только ошибки самого макроса
+func (r *registry) runMacro(ctx context.Context, cfg *Config, name string, body []string, arguments []string) error {
+	var err error
+	if r.macroDepth >= maxMacroDepth {
+		err = errors.New("too many nested macros")
+	} else if needed := macroArity(body); len(arguments) < needed {
+		err = fmt.Errorf("usage: %s expects %d arguments, got %d", name, needed, len(arguments))
+	}
+	if err != nil {
+		fmt.Fprintf(r.errOut, "Error executing %q command: %s\n", name, errorMessage(err))
+		return err
+	}
+
+	r.macroDepth++
+	defer func() { r.macroDepth-- }()
+
+	for _, command := range body {
+		if err := r.dispatch(ctx, cfg, substituteArgs(command, arguments)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// macroArity возвращает наибольший номер $n в теле макроса
+func macroArity(body []string) int {
+	arity := 0
+	for _, command := range body {
+		for _, word := range strings.Fields(command) {
+			if n, ok := positional(word); ok {
+				arity = max(arity, n)
+			}
+		}
+	}
+	return arity
+}
+
+// substituteArgs подставляет аргументы в команду макроса
+func substituteArgs(command string, arguments []string) string {
+	words := strings.Fields(command)
+	result := make([]string, 0, len(words))
+	for _, word := range words {
+		if word == "$@" {
+			result = append(result, arguments...)
+			continue
+		}
+		if n, ok := positional(word); ok {
+			result = append(result, arguments[n-1])
+			continue
+		}
+		result = append(result, word)
+	}
+	return strings.Join(result, " ")
+}
+
+// positional разбирает параметр вида $1..$9
+func positional(word string) (int, bool) {
+	digits, ok := strings.CutPrefix(word, "$")
+	if !ok || len(digits) != 1 {
+		return 0, false
+	}
+	n, err := strconv.Atoi(digits)
+	if err != nil || n < 1 {
+		return 0, false
+	}
+	return n, true
+}
+
+// userCommandNames возвращает имена пользовательских псевдонимов и макросов
+func userCommandNames(cfg *Config) []string {
+	names := make([]string, 0, len(cfg.settings.Aliases)+len(cfg.settings.Macros))
+	for name := range cfg.settings.Aliases {
+		names = append(names, name)
+	}
+	for name := range cfg.settings.Macros {
+		names = append(names, name)
+	}
+	return names
+}
+
+// Пользовательские псевдонимы для alias
+type aliasesView struct {
+	Aliases map[string]string `json:"aliases"`
+}
+
+func (v aliasesView) writeText(w io.Writer) {
+	if len(v.Aliases) == 0 {
+		fmt.Fprintln(w, "No aliases defined.")
+		return
+	}
+	for _, name := range sortedKeys(v.Aliases) {
+		fmt.Fprintf(w, "  %s = %s\n", name, v.Aliases[name])
+	}
+}
+
+// Пользовательские макросы для macro
+type macrosView struct {
+	Macros map[string][]string `json:"macros"`
+}
+
+func (v macrosView) writeText(w io.Writer) {
+	if len(v.Macros) == 0 {
+		fmt.Fprintln(w, "No macros defined.")
+		return
+	}
+	for _, name := range sortedKeys(v.Macros) {
+		fmt.Fprintf(w, "  %s = %s\n", name, strings.Join(v.Macros[name], "; "))
+	}
+}
+
+// sortedKeys возвращает ключи словаря по алфавиту
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// isMacroDefinition проверяет, что строка определяет макрос.
+// Такие строки в пакетном режиме не делятся по ';'
+func isMacroDefinition(line string) bool {
+	fields := strings.Fields(line)
+	return len(fields) > 2 && strings.ToLower(fields[0]) == "macro"
+}
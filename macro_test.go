@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAliasAndMacro(t *testing.T) {
+	server := newTestServer(t)
+	cfg, out, errOut := newTestConfig(t, server, "")
+	cfg.settingsPath = filepath.Join(t.TempDir(), "config.json")
+
+	commands := []string{
+		"alias m map",
+		"alias e explore",
+		"macro look explore $1; pokedex",
+		"m",
+		"e canalave-city-area",
+		"look canalave-city-area",
+	}
+	for _, command := range commands {
+		if err := cfg.registry.dispatch(context.Background(), cfg, command); err != nil {
+			t.Fatalf("%q: %v\nstderr: %s", command, err, errOut.String())
+		}
+	}
+
+	text := out.String()
+	if !strings.Contains(text, "eterna-city-area") {
+		t.Errorf("expected map output from alias, got %q", text)
+	}
+	if strings.Count(text, "Exploring canalave-city-area...") != 2 {
+		t.Errorf("expected explore output from alias and macro, got %q", text)
+	}
+	if !strings.Contains(text, "You have not caught any pokemon yet.") {
+		t.Errorf("expected pokedex output from macro, got %q", text)
+	}
+
+	// Псевдонимы и макросы сохраняются в конфиг-файле
+	settings, err := loadSettings(cfg.settingsPath)
+	if err != nil {
+		t.Fatalf("load settings: %v", err)
+	}
+	if settings.Aliases["m"] != "map" || settings.Aliases["e"] != "explore" {
+		t.Errorf("expected saved aliases, got %v", settings.Aliases)
+	}
+	if strings.Join(settings.Macros["look"], "|") != "explore $1|pokedex" {
+		t.Errorf("expected saved macro, got %v", settings.Macros)
+	}
+}
+
+func TestMacroErrors(t *testing.T) {
+	cases := []struct {
+		commands []string
+		expected string
+	}{
+		{commands: []string{"alias map mapb"}, expected: `"map" is a built-in command`},
+		{commands: []string{"alias dex map"}, expected: `"dex" is a built-in command`},
+		{commands: []string{"macro two catch $1; inspect $2", "two pikachu"}, expected: "usage: two expects 2 arguments, got 1"},
+		{commands: []string{"macro loop loop", "loop"}, expected: "too many nested macros"},
+		{commands: []string{"macro look pokedex", "alias look map"}, expected: `"look" is already a macro`},
+		{commands: []string{"alias -d nope"}, expected: `alias "nope" does not exist`},
+	}
+
+	for _, c := range cases {
+		cfg, _, errOut := newTestConfig(t, nil, "")
+
+		var err error
+		for _, command := range c.commands {
+			err = cfg.registry.dispatch(context.Background(), cfg, command)
+		}
+		if err == nil {
+			t.Errorf("%v: expected error", c.commands)
+		}
+		if !strings.Contains(errOut.String(), c.expected) {
+			t.Errorf("%v: expected %q, got %q", c.commands, c.expected, errOut.String())
+		}
+	}
+}
+
+func TestSubstituteArgs(t *testing.T) {
+	cases := []struct {
+		command  string
+		args     []string
+		expected string
+	}{
+		{command: "explore $1", args: []string{"canalave-city-area"}, expected: "explore canalave-city-area"},
+		{command: "catch $2", args: []string{"a", "b"}, expected: "catch b"},
+		{command: "cache ls $@", args: []string{"pokemon/"}, expected: "cache ls pokemon/"},
+		{command: "echo $10 $0 $x", args: []string{}, expected: "echo $10 $0 $x"},
+	}
+
+	for _, c := range cases {
+		if actual := substituteArgs(c.command, c.args); actual != c.expected {
+			t.Errorf("%q: expected %q, got %q", c.command, c.expected, actual)
+		}
+	}
+}
+
+func TestRunBatchMacroDefinition(t *testing.T) {
+	cfg, out, errOut := newTestConfig(t, nil, "macro two pokedex; pokedex\ntwo\n")
+
+	if status := cfg.registry.runBatch(context.Background(), cfg); status != 0 {
+		t.Fatalf("expected status 0, got %d: %s", status, errOut.String())
+	}
+	if strings.Count(out.String(), "You have not caught any pokemon yet.") != 2 {
+		t.Errorf("expected macro to run both commands, got %q", out.String())
+	}
+}
+
+func TestEmptyAliasFromConfig(t *testing.T) {
+	cfg, _, errOut := newTestConfig(t, nil, "")
+	cfg.settings.Aliases = map[string]string{"x": "  ", "dex": ""}
+
+	// Пустой псевдоним не раскрывается: x - неизвестная команда, dex - встроенный псевдоним
+	if err := cfg.registry.dispatch(context.Background(), cfg, "x"); err == nil {
+		t.Errorf("expected unknown command error, got %q", errOut.String())
+	}
+	if err := cfg.registry.dispatch(context.Background(), cfg, "dex"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
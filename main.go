@@ -1,111 +1,305 @@
 ﻿package main
 
 import (
-	"bufio"
+	"context"
+	"flag"
 	"fmt"
+	"io"
+	"net/http"
 	"os"
+	"os/signal"
+	"strings"
 	"time"
 
+	"github.com/CodeHunt7/go-pokedex/internal/lineedit"
+	"github.com/CodeHunt7/go-pokedex/internal/pokeapi"
 	"github.com/CodeHunt7/go-pokedex/internal/pokecache"
 )
 
-// Структура для CLI команд
-type cliCommand struct {
-	name        string
-	description string
-	callback    func(*Config, []string) error
-}
-
-// Создаем переменную для всех команд
-var commands map[string]cliCommand
+// Интервал очистки кэша
+const cacheInterval = 1 * time.Minute
 
 func main() {
+	// Разбираем флаги командной строки
+	apiBase := flag.String("api-base", "", "PokeAPI base URL (overrides $"+apiBaseEnv+" and the config file)")
+	settingsPath := flag.String("config", defaultSettingsPath(), "path to the config file")
+	saveDir := flag.String("save-dir", defaultSaveDir(), "directory with profile save slots")
+	profile := flag.String("profile", "", "trainer profile to start with (defaults to the last used one)")
+	cacheDir := flag.String("cache-dir", pokecache.DefaultDir(), "directory for the on-disk cache, empty to keep it in memory only")
+	cacheMaxEntries := flag.Int("cache-max-entries", 0, "maximum number of cached responses, 0 for no limit")
+	cacheMaxStale := flag.Duration("cache-max-stale", 7*24*time.Hour, "how long to keep serving expired responses while they refresh in the background, 0 to disable")
+	cacheMaxBytes := flag.Int("cache-max-bytes", 64<<20, "maximum size of cached responses in bytes, 0 for no limit")
+	commandList := flag.String("c", "", "run the given commands separated by ';' and exit")
+	scriptPath := flag.String("script", "", "run commands from the given file and exit")
+	output := flag.String("output", outputText, "output format for command results: text or json")
+	maxAttempts := flag.Int("max-attempts", pokeapi.DefaultMaxAttempts, "how many times to try a PokeAPI request that fails with a transient error, 1 to disable retries")
+	rate := flag.Float64("rate", pokeapi.DefaultRate, "maximum PokeAPI requests per second, cached responses do not count, 0 for no limit")
+	burst := flag.Int("burst", pokeapi.DefaultBurst, "how many PokeAPI requests may go out at once before --rate applies")
+	verbose := flag.Bool("verbose", false, "report rate limit waits on stderr")
+	offline := flag.Bool("offline", false, "answer every PokeAPI request from the snapshot instead of the network")
+	snapshotPath := flag.String("snapshot", pokeapi.DefaultSnapshotPath(), "snapshot archive written by 'snapshot build' and read in --offline mode")
+	historyPath := flag.String("history", lineedit.DefaultHistoryPath(), "file with the command history of interactive sessions, empty to disable")
+	flag.Parse()
+
+	if err := validateOutput(*output); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	// Читаем конфиг-файл
+	settings, err := loadSettings(*settingsPath)
+	if err != nil {
+		// Не перезаписываем файл, который не смогли прочитать
+		fmt.Fprintln(os.Stderr, "Error reading config file:", err)
+		*settingsPath = ""
+	}
+
+	// Делаем кэш на диске, если не получилось - в памяти
+	cacheOpts := []pokecache.Option{
+		pokecache.WithMaxEntries(*cacheMaxEntries),
+		pokecache.WithMaxBytes(*cacheMaxBytes),
+		pokecache.WithStaleWhileRevalidate(*cacheMaxStale),
+	}
+	var cache *pokecache.Cache
+	if *cacheDir != "" {
+		cache, err = pokecache.NewDiskCache(cacheInterval, *cacheDir, cacheOpts...)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error opening cache directory, using memory cache:", err)
+		}
+	}
+	if cache == nil {
+		cache = pokecache.NewCache(cacheInterval, cacheOpts...)
+	}
+
+	// HTTP клиент с ограничением частоты и повторами при временных ошибках API.
+	// Каждый повтор тоже проходит через ограничитель
+	limiter := pokeapi.NewRateLimitTransport(nil, *rate, *burst)
+	if *verbose {
+		limiter.OnWait = func(wait time.Duration) {
+			fmt.Fprintf(os.Stderr, "Rate limit: waiting %s before the next request\n", wait.Round(time.Millisecond))
+		}
+	}
+	httpClient := &http.Client{
+		Timeout:   pokeapi.DefaultTimeout,
+		Transport: pokeapi.NewRetryTransport(limiter, *maxAttempts),
+	}
+	apiBaseURL := resolveAPIBase(*apiBase, settings)
+
+	// Без сети все запросы идут в снимок, снятый с его же адреса API
+	if *offline {
+		snapshot, err := openSnapshot(*snapshotPath)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error opening snapshot:", err)
+			os.Exit(1)
+		}
+		defer snapshot.Close()
+		httpClient = &http.Client{Transport: snapshot}
+		apiBaseURL = snapshot.Manifest.BaseURL
+	}
+
 	// Делаем конфиг
-	cache := pokecache.NewCache(1 * time.Minute)
 	cfg := &Config{
+		pokeapiClient: pokeapi.NewClient(apiBaseURL, httpClient, cache),
 		pokeCache: cache,
-		Pokedex: make(map[string]PokemonResponse),
+		Pokedex: make(map[string]CaughtPokemon),
+		saveDir: *saveDir,
+		settingsPath: *settingsPath,
+		settings: settings,
+		output: *output,
+		offline: *offline,
+		snapshotPath: *snapshotPath,
 	}
 
-	// Инициализируем команды
-	commands = map[string]cliCommand{
-		"exit": {
-			name:        "exit",
-			description: "Exit the Pokedex",
-			callback:    commandExit,
-		},
-		"help": {
-			name: "help",
-			description: "Displays a help message",
-			callback: commandHelp,
-		},
-		"map": {
-			name: "map",
-			description: "Displays the names of 20 location areas",
-			callback: commandMap,
-		},
-		"mapb": {
-			name: "mapb",
-			description: "Displays the names of the previous 20 location areas",
-			callback: commandMapBack,
-		},
-		"explore": {
-			name: "explore",
-			description: "See a list of all the Pokémon located in location",
-			callback: commandExplore,
-		},
-		"catch": {
-			name: "catch",
-			description: "Use pokemon name and try to catch it",
-			callback: commandCatch,
-		},
-		"inspect": {
-			name: "inspect",
-			description: "View details about caught pokemon",
-			callback: commandInspect,
-		},
-		"pokedex": {
-			name: "pokedex",
-			description: "View all caught pokemon",
-			callback: commandPokedex,
-		},
-	}
-	
-	// Создаем сканнер для чтения ввода
-	scanner := bufio.NewScanner(os.Stdin)
-	
-	// Основной бесконечный цикл REPL
-	for {
-		fmt.Print("Pokedex > ")
-
-		if scanner.Scan() {
-			// читаем ввод, если пустой перезапуск цикла
-			userInput := cleanInput(scanner.Text())
-			if len(userInput) == 0 {
-				fmt.Println("Type a command, please. 'Help' to see available commands.")
-				continue
-			}
-
-			// вычленяем команду и проверяем наличие параметров
-			commandName := userInput[0]
-			arguments := []string{}
-			if len(userInput) > 1 {
-				arguments = userInput[1:] // Берем все, что после команды
-			}
-
-			// Ищем команду и вызываем
-			if cmd, exists := commands[commandName]; exists {
-				if err := cmd.callback(cfg, arguments); err != nil {
-					fmt.Fprintf(os.Stderr, "Error executing %q command: %v\n", commandName, err)
-				}
-			} else {
-				fmt.Println("Unknown command")
-			}
+	// Загружаем сохранение прошлой сессии
+	if *saveDir == defaultSaveDir() {
+		if err := migrateLegacySave(*saveDir); err != nil {
+			fmt.Fprintln(os.Stderr, "Error moving old save file into the default profile:", err)
 		}
+	}
+	startProfile := *profile
+	if startProfile == "" {
+		startProfile = settings.Profile
+	}
+	if startProfile == "" {
+		startProfile = defaultProfile
+	}
+	// Имя приходит из флага или конфиг-файла и становится путем к слоту
+	if err := checkProfileName(startProfile); err != nil {
+		fmt.Fprintln(os.Stderr, "Error choosing profile, using the default one:", err)
+		startProfile = defaultProfile
+	}
+	if err := activateProfile(cfg, startProfile); err != nil {
+		// Не затираем файл, который не смогли прочитать
+		fmt.Fprintln(os.Stderr, "Error loading save file, autosave is disabled:", err)
+	} else if err := rememberProfile(cfg); err != nil {
+		// Автосохранение работает, не запомнился только выбор профиля
+		fmt.Fprintln(os.Stderr, "Error saving config file:", err)
+	}
+
+	// Выбираем, откуда читать команды: флаг, файл или ввод
+	in := io.Reader(os.Stdin)
+	interactive := false
+	switch {
+	case *commandList != "":
+		in = strings.NewReader(*commandList)
+	case *scriptPath != "":
+		script, err := os.Open(*scriptPath)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error opening script:", err)
+			os.Exit(1)
+		}
+		defer script.Close()
+		in = script
+	default:
+		interactive = lineedit.IsTerminal(int(os.Stdin.Fd()))
+	}
+
+	// Инициализируем команды
+	cfg.registry = newRegistry(in, os.Stdout, os.Stderr)
+	cfg.registry.historyPath = *historyPath
+	registerCommands(cfg.registry)
 
-		if err := scanner.Err(); err != nil {
-			fmt.Fprintln(os.Stderr, "Error reading input:", err)
-		}	
+	// Интерактивный режим с приглашением или пакетный без него
+	status := 0
+	if interactive {
+		// Ctrl-C отменяет текущую команду, а не завершает процесс
+		interrupts := make(chan os.Signal, 1)
+		signal.Notify(interrupts, os.Interrupt)
+		cfg.registry.interrupts = interrupts
+		cfg.registry.runREPL(cfg)
+		signal.Stop(interrupts)
+	} else {
+		// Ctrl-C останавливает пакет, но состояние все равно сохраняется
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		status = cfg.registry.runBatch(ctx, cfg)
+		stop()
 	}
-	
-}
\ No newline at end of file
+
+	// Сохраняем прогресс перед выходом
+	if err := shutdown(cfg); err != nil {
+		fmt.Fprintln(os.Stderr, "Error saving the Pokedex:", err)
+		status = 1
+	}
+	os.Exit(status)
+}
+
+// shutdown сохраняет активный профиль и останавливает кэш
+func shutdown(cfg *Config) error {
+	cfg.pokeCache.Close()
+
+	if !cfg.autosave {
+		return nil
+	}
+	return saveGame(cfg, cfg.savePath)
+}
+
+// registerCommands добавляет все команды Pokedex
+func registerCommands(r *registry) {
+	r.register(cliCommand{
+		name:        "exit",
+		description: "Exit the Pokedex",
+		args:        &argSpec{min: 0, max: 0, usage: "exit"},
+		aliases:     []string{"quit"},
+		callback:    commandExit,
+	})
+	r.register(cliCommand{
+		name:        "help",
+		description: "Displays a help message",
+		args:        &argSpec{min: 0, max: 1, usage: "help [command]"},
+		aliases:     []string{"?"},
+		examples:    []string{"help", "help catch"},
+		callback:    commandHelp,
+	})
+	r.register(cliCommand{
+		name:        "map",
+		description: "Displays the names of 20 location areas",
+		args:        &argSpec{min: 0, max: 0, usage: "map"},
+		callback:    commandMap,
+	})
+	r.register(cliCommand{
+		name:        "mapb",
+		description: "Displays the names of the previous 20 location areas",
+		args:        &argSpec{min: 0, max: 0, usage: "mapb"},
+		aliases:     []string{"back"},
+		callback:    commandMapBack,
+	})
+	r.register(cliCommand{
+		name:        "explore",
+		description: "See a list of all the Pokémon located in location",
+		args:        &argSpec{min: 1, max: 1, usage: "explore <location-area>"},
+		examples:    []string{"explore canalave-city-area"},
+		callback:    commandExplore,
+	})
+	r.register(cliCommand{
+		name:        "catch",
+		description: "Use pokemon name and try to catch it",
+		args:        &argSpec{min: 1, max: 1, usage: "catch <pokemon>"},
+		examples:    []string{"catch pikachu"},
+		callback:    commandCatch,
+	})
+	r.register(cliCommand{
+		name:        "inspect",
+		description: "View details about caught pokemon",
+		args:        &argSpec{min: 1, max: 1, usage: "inspect <pokemon>"},
+		examples:    []string{"inspect pikachu", "inspect pikachu -o json"},
+		callback:    commandInspect,
+	})
+	r.register(cliCommand{
+		name:        "pokedex",
+		description: "View all caught pokemon",
+		args:        &argSpec{min: 0, max: 0, usage: "pokedex"},
+		aliases:     []string{"dex"},
+		callback:    commandPokedex,
+	})
+	r.register(cliCommand{
+		name:        "save",
+		description: "Save caught pokemon and map position, optionally to the given file",
+		args:        &argSpec{min: 0, max: 1, usage: "save [file]"},
+		examples:    []string{"save", "save /tmp/backup.json"},
+		callback:    commandSave,
+		keepCase:    true,
+	})
+	r.register(cliCommand{
+		name:        "load",
+		description: "Load caught pokemon and map position, optionally from the given file",
+		args:        &argSpec{min: 0, max: 1, usage: "load [file]"},
+		examples:    []string{"load", "load /tmp/backup.json"},
+		callback:    commandLoad,
+		keepCase:    true,
+	})
+	r.register(cliCommand{
+		name:        "snapshot",
+		description: "Download location areas, pokemon and species into an archive for --offline mode",
+		args:        &argSpec{min: 1, max: 1, usage: "snapshot build"},
+		examples:    []string{"snapshot build"},
+		callback:    commandSnapshot,
+	})
+	r.register(cliCommand{
+		name:        "profile",
+		description: "Manage trainer profiles: new <name>, list, switch <name>, delete <name>",
+		args:        &argSpec{min: 1, max: 2, usage: "profile new|list|switch|delete [name]"},
+		examples:    []string{"profile list", "profile new misty", "profile switch misty"},
+		callback:    commandProfile,
+	})
+	r.register(cliCommand{
+		name:        "alias",
+		description: "List, define or delete (-d) command aliases",
+		args:        &argSpec{min: 0, max: anyArgs, usage: "alias [name [command [args...]]] | alias -d <name>"},
+		examples:    []string{"alias", "alias m map", "alias e explore", "alias -d m"},
+		callback:    commandAlias,
+	})
+	r.register(cliCommand{
+		name:        "macro",
+		description: "List, define or delete (-d) macros: commands separated by ';' with $1..$9 and $@ for arguments",
+		args:        &argSpec{min: 0, max: anyArgs, usage: "macro [name [command; command...]] | macro -d <name>"},
+		examples:    []string{"macro hunt explore $1; catch $2", "hunt canalave-city-area tentacool", "macro -d hunt"},
+		callback:    commandMacro,
+	})
+	r.register(cliCommand{
+		name:        "cache",
+		description: "Inspect and manage the response cache: stats, ls [prefix], purge [prefix], warm <resource>",
+		args:        &argSpec{min: 1, max: 2, usage: "cache stats|ls|purge|warm [prefix|resource]"},
+		examples:    []string{"cache stats", "cache ls pokemon/", "cache purge location-area/", "cache warm location-area"},
+		callback:    commandCache,
+	})
+}
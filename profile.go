@@ -0,0 +1,252 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Профиль, который используется, если другой не выбран
+const defaultProfile = "default"
+
+// Допустимые имена профилей, они же имена файлов
+var profileNameRe = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// defaultSaveDir возвращает папку со слотами профилей по умолчанию
+func defaultSaveDir() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "pokedex", "profiles")
+}
+
+// profileSlot возвращает путь к файлу сохранения профиля
+func profileSlot(saveDir, profile string) string {
+	return filepath.Join(saveDir, profile+".json")
+}
+
+// migrateLegacySave переносит файл сохранения из версий без профилей
+// в слот профиля по умолчанию
+func migrateLegacySave(saveDir string) error {
+	legacy := defaultSavePath()
+	slot := profileSlot(saveDir, defaultProfile)
+	if legacy == "" {
+		return nil
+	}
+	if _, err := os.Stat(slot); !errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if _, err := os.Stat(legacy); err != nil {
+		return nil
+	}
+
+	if err := os.MkdirAll(saveDir, 0o755); err != nil {
+		return err
+	}
+	return os.Rename(legacy, slot)
+}
+
+// listProfiles возвращает имена профилей, у которых есть слот
+func listProfiles(saveDir string) ([]string, error) {
+	files, err := os.ReadDir(saveDir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	profiles := []string{}
+	for _, file := range files {
+		name, ok := strings.CutSuffix(file.Name(), ".json")
+		if !ok || file.IsDir() || !profileNameRe.MatchString(name) {
+			continue
+		}
+		profiles = append(profiles, name)
+	}
+	sort.Strings(profiles)
+	return profiles, nil
+}
+
+// checkProfileName проверяет имя профиля: оно становится именем файла
+// и не должно выводить за пределы папки со слотами
+func checkProfileName(name string) error {
+	if !profileNameRe.MatchString(name) {
+		return fmt.Errorf("invalid profile name %q: use lowercase letters, digits, '-' and '_'", name)
+	}
+	return nil
+}
+
+// activateProfile делает профиль активным и загружает его слот.
+// Профиль с недопустимым именем не активируется
+func activateProfile(cfg *Config, profile string) error {
+	if err := checkProfileName(profile); err != nil {
+		return err
+	}
+
+	cfg.profile = profile
+	cfg.savePath = profileSlot(cfg.saveDir, profile)
+	cfg.Pokedex = make(map[string]CaughtPokemon)
+	cfg.Next = ""
+	cfg.Previous = ""
+
+	if _, err := loadGame(cfg, cfg.savePath); err != nil {
+		// Не затираем файл, который не смогли прочитать
+		cfg.autosave = false
+		return err
+	}
+	cfg.autosave = true
+	return nil
+}
+
+// rememberProfile запоминает активный профиль в конфиге до следующего запуска
+func rememberProfile(cfg *Config) error {
+	if cfg.settings.Profile == cfg.profile || cfg.settingsPath == "" {
+		return nil
+	}
+	cfg.settings.Profile = cfg.profile
+	return saveSettings(cfg.settingsPath, cfg.settings)
+}
+
+// Подкоманды команды profile
+const profileUsage = "usage: profile new <name> | profile list | profile switch <name> | profile delete <name>"
+
+func commandProfile(ctx context.Context, cfg *Config, parameters []string) error {
+	if len(parameters) == 0 {
+		return errors.New(profileUsage)
+	}
+	if parameters[0] == "list" {
+		return profileList(cfg)
+	}
+	if len(parameters) < 2 {
+		return errors.New(profileUsage)
+	}
+
+	name := parameters[1]
+	if err := checkProfileName(name); err != nil {
+		return err
+	}
+
+	switch parameters[0] {
+	case "new":
+		return profileNew(cfg, name)
+	case "switch":
+		return profileSwitch(cfg, name)
+	case "delete":
+		return profileDelete(cfg, name)
+	default:
+		return errors.New(profileUsage)
+	}
+}
+
+// profileList выводит профили и отмечает активный
+func profileList(cfg *Config) error {
+	profiles, err := listProfiles(cfg.saveDir)
+	if err != nil {
+		return err
+	}
+
+	// Активный профиль может быть еще не сохранен
+	if i := sort.SearchStrings(profiles, cfg.profile); i == len(profiles) || profiles[i] != cfg.profile {
+		profiles = append(profiles, cfg.profile)
+		sort.Strings(profiles)
+	}
+
+	return render(cfg, profilesView{Profiles: profiles, Active: cfg.profile})
+}
+
+// Список профилей для profile list
+type profilesView struct {
+	Profiles []string `json:"profiles"`
+	Active   string   `json:"active"`
+}
+
+func (v profilesView) writeText(w io.Writer) {
+	fmt.Fprintln(w, "Profiles:")
+	for _, profile := range v.Profiles {
+		marker := " "
+		if profile == v.Active {
+			marker = "*"
+		}
+		fmt.Fprintf(w, " %s %s\n", marker, profile)
+	}
+}
+
+// profileNew создает пустой профиль и переключается на него
+func profileNew(cfg *Config, name string) error {
+	slot := profileSlot(cfg.saveDir, name)
+	if _, err := os.Stat(slot); err == nil || name == cfg.profile {
+		return fmt.Errorf("profile %q already exists", name)
+	}
+
+	if err := saveActiveProfile(cfg); err != nil {
+		return err
+	}
+	if err := activateProfile(cfg, name); err != nil {
+		return err
+	}
+	if err := rememberProfile(cfg); err != nil {
+		return err
+	}
+	// Сразу создаем слот, чтобы профиль появился в списке
+	if err := saveGame(cfg, cfg.savePath); err != nil {
+		return err
+	}
+
+	return render(cfg, messageView{fmt.Sprintf("Created profile %s", name)})
+}
+
+// profileSwitch сохраняет текущий профиль и загружает другой
+func profileSwitch(cfg *Config, name string) error {
+	if name == cfg.profile {
+		return render(cfg, messageView{fmt.Sprintf("Profile %s is already active", name)})
+	}
+	if _, err := os.Stat(profileSlot(cfg.saveDir, name)); err != nil {
+		return fmt.Errorf("profile %q does not exist", name)
+	}
+
+	if err := saveActiveProfile(cfg); err != nil {
+		return err
+	}
+	if err := activateProfile(cfg, name); err != nil {
+		return err
+	}
+	if err := rememberProfile(cfg); err != nil {
+		return err
+	}
+
+	return render(cfg, messageView{fmt.Sprintf("Switched to profile %s with %d pokemon", name, len(cfg.Pokedex))})
+}
+
+// profileDelete удаляет слот неактивного профиля
+func profileDelete(cfg *Config, name string) error {
+	if name == cfg.profile {
+		return errors.New("cannot delete the active profile, switch to another one first")
+	}
+
+	err := os.Remove(profileSlot(cfg.saveDir, name))
+	if errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("profile %q does not exist", name)
+	}
+	if err != nil {
+		return err
+	}
+
+	return render(cfg, messageView{fmt.Sprintf("Deleted profile %s", name)})
+}
+
+// saveActiveProfile сохраняет текущий профиль перед переключением
+func saveActiveProfile(cfg *Config) error {
+	if !cfg.autosave {
+		return nil
+	}
+	return saveGame(cfg, cfg.savePath)
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/CodeHunt7/go-pokedex/internal/pokeapi"
+)
+
+func TestProfiles(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &Config{
+		saveDir:       filepath.Join(dir, "profiles"),
+		settingsPath:  filepath.Join(dir, "config.json"),
+		registry:      newRegistry(nil, io.Discard, io.Discard),
+		pokeapiClient: pokeapi.NewClient("", nil, nil),
+	}
+	if err := activateProfile(cfg, defaultProfile); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cfg.Pokedex["pikachu"] = CaughtPokemon{}
+
+	// Новый профиль начинается с пустого Pokedex
+	if err := commandProfile(context.Background(), cfg, []string{"new", "misty"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.profile != "misty" || len(cfg.Pokedex) != 0 {
+		t.Errorf("expected empty misty profile, got %s with %d pokemon", cfg.profile, len(cfg.Pokedex))
+	}
+	cfg.Pokedex["staryu"] = CaughtPokemon{}
+
+	// Возвращаемся и видим сохраненного покемона
+	if err := commandProfile(context.Background(), cfg, []string{"switch", defaultProfile}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := cfg.Pokedex["pikachu"]; !ok || len(cfg.Pokedex) != 1 {
+		t.Errorf("expected default profile pokedex, got %v", cfg.Pokedex)
+	}
+
+	profiles, err := listProfiles(cfg.saveDir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(profiles, []string{"default", "misty"}) {
+		t.Errorf("unexpected profiles: %v", profiles)
+	}
+
+	// Активный профиль удалить нельзя, неактивный можно
+	if err := commandProfile(context.Background(), cfg, []string{"delete", defaultProfile}); err == nil {
+		t.Errorf("expected error deleting active profile")
+	}
+	if err := commandProfile(context.Background(), cfg, []string{"delete", "misty"}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	// Активный профиль запомнен в конфиг-файле
+	settings, err := loadSettings(cfg.settingsPath)
+	if err != nil || settings.Profile != defaultProfile {
+		t.Errorf("expected active profile in settings, got %q (%v)", settings.Profile, err)
+	}
+}
+
+func TestActivateProfileRejectsBadNames(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &Config{
+		saveDir:       filepath.Join(dir, "a", "profiles"),
+		registry:      newRegistry(nil, io.Discard, io.Discard),
+		pokeapiClient: pokeapi.NewClient("", nil, nil),
+	}
+	if err := activateProfile(cfg, defaultProfile); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, name := range []string{"../../escape", "Misty", "", "a/b"} {
+		if err := activateProfile(cfg, name); err == nil {
+			t.Errorf("%q: expected invalid name error", name)
+		}
+		if cfg.profile != defaultProfile || cfg.savePath != profileSlot(cfg.saveDir, defaultProfile) {
+			t.Errorf("%q: expected active profile to stay default, got %s at %s", name, cfg.profile, cfg.savePath)
+		}
+	}
+
+	if err := saveGame(cfg, cfg.savePath); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "escape.json")); !os.IsNotExist(err) {
+		t.Errorf("expected nothing written outside the save dir")
+	}
+}
@@ -0,0 +1,342 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/CodeHunt7/go-pokedex/internal/lineedit"
+)
+
+// Структура для CLI команд
+type cliCommand struct {
+	name        string
+	description string
+	args        *argSpec // nil - аргументы не проверяются
+	aliases     []string
+	examples    []string
+	callback    func(context.Context, *Config, []string) error
+	keepCase    bool // аргументы не приводятся к нижнему регистру, например пути к файлам
+}
+
+// Описание аргументов команды
+type argSpec struct {
+	min   int
+	max   int    // anyArgs - без ограничения
+	usage string // например "inspect <pokemon>"
+}
+
+// Команда принимает любое количество аргументов
+const anyArgs = -1
+
+// errExit возвращает команда exit, чтобы остановить цикл команд
+var errExit = errors.New("exit")
+
+// Код выхода пакетного режима, прерванного Ctrl-C
+const interruptedStatus = 130
+
+// registry хранит команды и ввод-вывод, с которым они работают
+type registry struct {
+	commands map[string]cliCommand
+	aliases  map[string]string // псевдоним -> имя команды
+	in       io.Reader
+	out      io.Writer
+	errOut   io.Writer
+
+	historyPath string           // файл истории REPL, пустой - история не сохраняется
+	interrupts  <-chan os.Signal // Ctrl-C во время команды, nil - не отслеживается
+	macroDepth  int              // вложенность выполняемых макросов
+}
+
+// newRegistry создает пустой набор команд для заданного ввода-вывода
+func newRegistry(in io.Reader, out, errOut io.Writer) *registry {
+	return &registry{
+		commands: make(map[string]cliCommand),
+		aliases:  make(map[string]string),
+		in:       in,
+		out:      out,
+		errOut:   errOut,
+	}
+}
+
+// register добавляет команду вместе с ее псевдонимами
+func (r *registry) register(cmd cliCommand) {
+	r.commands[cmd.name] = cmd
+	for _, alias := range cmd.aliases {
+		r.aliases[alias] = cmd.name
+	}
+}
+
+// lookup ищет команду по имени или псевдониму
+func (r *registry) lookup(name string) (cliCommand, bool) {
+	if target, isAlias := r.aliases[name]; isAlias {
+		name = target
+	}
+	cmd, exists := r.commands[name]
+	return cmd, exists
+}
+
+// sorted возвращает команды в алфавитном порядке
+func (r *registry) sorted() []cliCommand {
+	cmds := make([]cliCommand, 0, len(r.commands))
+	for _, cmd := range r.commands {
+		cmds = append(cmds, cmd)
+	}
+	sort.Slice(cmds, func(i, j int) bool {
+		return cmds[i].name < cmds[j].name
+	})
+	return cmds
+}
+
+// usage возвращает строку использования команды
+func (cmd cliCommand) usage() string {
+	if cmd.args == nil || cmd.args.usage == "" {
+		return cmd.name
+	}
+	return cmd.args.usage
+}
+
+// checkArgs проверяет количество аргументов по описанию команды
+func (cmd cliCommand) checkArgs(arguments []string) error {
+	if cmd.args == nil {
+		return nil
+	}
+	if len(arguments) < cmd.args.min || (cmd.args.max != anyArgs && len(arguments) > cmd.args.max) {
+		return fmt.Errorf("usage: %s", cmd.usage())
+	}
+	return nil
+}
+
+// dispatch выполняет одну строку ввода и возвращает ошибку команды.
+// Ошибки сразу выводятся в errOut, errExit означает выход
+func (r *registry) dispatch(ctx context.Context, cfg *Config, line string) error {
+	// Регистр слов сохраняем, пока не станет ясно, нужен ли он команде
+	userInput := strings.Fields(line)
+	if len(userInput) == 0 {
+		return nil
+	}
+
+	// Пользовательские псевдонимы раскрываются до поиска команды
+	userInput = expandAlias(cfg, userInput)
+
+	// вычленяем команду и проверяем наличие параметров
+	commandName := strings.ToLower(userInput[0])
+	arguments := userInput[1:] // Берем все, что после команды
+
+	// Макросы выполняют свои команды через dispatch
+	if body, isMacro := cfg.settings.Macros[commandName]; isMacro {
+		return r.runMacro(ctx, cfg, commandName, body, arguments)
+	}
+
+	// Ищем команду
+	cmd, exists := r.lookup(commandName)
+	if !exists {
+		fmt.Fprintln(r.errOut, didYouMean("Unknown command", suggest(commandName, commandNames(cfg))))
+		return fmt.Errorf("unknown command %q", commandName)
+	}
+
+	if !cmd.keepCase {
+		arguments = cleanInput(strings.Join(arguments, " "))
+	}
+
+	err := r.call(ctx, cfg, cmd, arguments)
+	switch {
+	case err == nil || errors.Is(err, errExit):
+	case errors.Is(err, context.Canceled):
+		fmt.Fprintf(r.errOut, "Command %q cancelled\n", commandName)
+	default:
+		fmt.Fprintf(r.errOut, "Error executing %q command: %s\n", commandName, errorMessage(err))
+	}
+	return err
+}
+
+// call проверяет аргументы и вызывает команду
+func (r *registry) call(ctx context.Context, cfg *Config, cmd cliCommand, arguments []string) error {
+	// Формат вывода можно поменять для одной команды через -o json
+	arguments, format, err := parseOutputFlag(arguments)
+	if err != nil {
+		return err
+	}
+	if format != "" {
+		defer func(previous string) { cfg.output = previous }(cfg.output)
+		cfg.output = format
+	}
+
+	if err := cmd.checkArgs(arguments); err != nil {
+		return err
+	}
+	return cmd.callback(ctx, cfg, arguments)
+}
+
+// lineReader читает одну строку ввода с приглашением
+type lineReader interface {
+	ReadLine(prompt string) (string, error)
+}
+
+// scannerReader читает строки без редактирования, когда ввод не терминал
+type scannerReader struct {
+	scanner *bufio.Scanner
+	out     io.Writer
+}
+
+func (s scannerReader) ReadLine(prompt string) (string, error) {
+	fmt.Fprint(s.out, prompt)
+	if !s.scanner.Scan() {
+		if err := s.scanner.Err(); err != nil {
+			return "", err
+		}
+		return "", io.EOF
+	}
+	return s.scanner.Text(), nil
+}
+
+// newLineReader возвращает редактор строк с автодополнением для терминала
+// или построчное чтение для всего остального
+func (r *registry) newLineReader(cfg *Config) (lineReader, *lineedit.History) {
+	f, ok := r.in.(*os.File)
+	if !ok || !lineedit.IsTerminal(int(f.Fd())) {
+		return scannerReader{scanner: bufio.NewScanner(r.in), out: r.out}, nil
+	}
+
+	history, err := lineedit.LoadHistory(r.historyPath, lineedit.DefaultHistorySize)
+	if err != nil {
+		fmt.Fprintln(r.errOut, "Error reading history file:", err)
+	}
+	editor := lineedit.NewTerminal(f, r.out, history)
+	editor.SetCompleter(func(line string) (int, []string) {
+		ctx, cancel := context.WithTimeout(context.Background(), completionTimeout)
+		defer cancel()
+		return complete(ctx, cfg, line)
+	})
+	return editor, history
+}
+
+// runREPL читает команды с приглашением, пока не будет exit или конца ввода.
+// Ctrl-C на приглашении сбрасывает строку, второй Ctrl-C подряд завершает работу
+func (r *registry) runREPL(cfg *Config) {
+	reader, history := r.newLineReader(cfg)
+	if history != nil {
+		defer func() {
+			if err := history.Save(r.historyPath); err != nil {
+				fmt.Fprintln(r.errOut, "Error saving history file:", err)
+			}
+		}()
+	}
+
+	// Основной цикл REPL
+	interrupted := false
+	for {
+		line, err := reader.ReadLine(fmt.Sprintf("Pokedex (%s) > ", cfg.profile))
+		if errors.Is(err, lineedit.ErrInterrupted) {
+			if interrupted {
+				break
+			}
+			interrupted = true
+			fmt.Fprintln(r.out, "(To exit, press Ctrl-C again or Ctrl-D)")
+			continue
+		}
+		interrupted = false
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				fmt.Fprintln(r.errOut, "Error reading input:", err)
+			}
+			break
+		}
+
+		// читаем ввод, если пустой перезапуск цикла
+		if len(cleanInput(line)) == 0 {
+			fmt.Fprintln(r.out, "Type a command, please. 'Help' to see available commands.")
+			continue
+		}
+		quit, err := r.runCommand(cfg, line)
+		if errors.Is(err, errExit) {
+			return
+		}
+		if quit {
+			break
+		}
+	}
+
+	// Ввод закончился (Ctrl-D или Ctrl-C), выходим как по команде exit
+	fmt.Fprintln(r.out)
+	commandExit(context.Background(), cfg, nil)
+}
+
+// runCommand выполняет строку и отменяет ее по сигналу из interrupts.
+// Первый сигнал отменяет команду, второй просит завершить работу,
+// как только команда вернется. quit сообщает о такой просьбе
+func (r *registry) runCommand(cfg *Config, line string) (quit bool, err error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Сигналы, пришедшие на приглашении, к этой команде не относятся
+	for len(r.interrupts) > 0 {
+		<-r.interrupts
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- r.dispatch(ctx, cfg, line) }()
+
+	for {
+		select {
+		case err := <-done:
+			return quit, err
+		case <-r.interrupts:
+			if ctx.Err() == nil {
+				// Пишем до отмены, чтобы не пересечься с выводом самой команды
+				fmt.Fprintln(r.errOut, "\nCancelling... press Ctrl-C again to exit")
+				cancel()
+			} else {
+				quit = true
+			}
+		}
+	}
+}
+
+// splitCommands делит строку пакетного режима на команды по ';'.
+// Определение макроса не делится: ';' там разделяет тело макроса
+func splitCommands(line string) []string {
+	if isMacroDefinition(line) {
+		return []string{line}
+	}
+	return strings.Split(line, ";")
+}
+
+// runBatch выполняет команды без приглашения и возвращает код выхода:
+// 1, если хотя бы одна команда завершилась ошибкой.
+// Команды разделяются переводом строки или ';', строки с '#' пропускаются
+// Отмена ctx (Ctrl-C) останавливает выполнение с кодом interruptedStatus
+func (r *registry) runBatch(ctx context.Context, cfg *Config) int {
+	status := 0
+	scanner := bufio.NewScanner(r.in)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		for _, command := range splitCommands(line) {
+			if ctx.Err() != nil {
+				fmt.Fprintln(r.errOut, "Interrupted")
+				return interruptedStatus
+			}
+			err := r.dispatch(ctx, cfg, command)
+			if errors.Is(err, errExit) {
+				return status
+			}
+			if err != nil {
+				status = 1
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		fmt.Fprintln(r.errOut, "Error reading input:", err)
+		status = 1
+	}
+
+	return status
+}
@@ -0,0 +1,290 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/CodeHunt7/go-pokedex/internal/pokeapi"
+	"github.com/CodeHunt7/go-pokedex/internal/pokecache"
+)
+
+// newTestConfig собирает конфиг со всеми командами, вводом input
+// и буферами вместо stdout и stderr. Запросы к API уходят на server
+func newTestConfig(t *testing.T, server *httptest.Server, input string) (*Config, *bytes.Buffer, *bytes.Buffer) {
+	t.Helper()
+
+	cache := pokecache.NewCache(time.Minute)
+	t.Cleanup(cache.Close)
+
+	// Без сервера запросы сразу падают, без повторов
+	baseURL := "http://127.0.0.1:0/"
+	httpClient := &http.Client{}
+	if server != nil {
+		baseURL = server.URL
+		httpClient = server.Client()
+	}
+
+	out := &bytes.Buffer{}
+	errOut := &bytes.Buffer{}
+	cfg := &Config{
+		pokeapiClient: pokeapi.NewClient(baseURL, httpClient, cache),
+		pokeCache:     cache,
+		Pokedex:       map[string]CaughtPokemon{},
+		profile:       defaultProfile,
+		registry:      newRegistry(strings.NewReader(input), out, errOut),
+		output:        outputText,
+	}
+	registerCommands(cfg.registry)
+
+	return cfg, out, errOut
+}
+
+// newTestServer отдает фиксированные ответы PokeAPI
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/location-area/":
+			fmt.Fprint(w, `{"next": "", "previous": null,
+				"results": [{"name": "canalave-city-area"}, {"name": "eterna-city-area"}]}`)
+		case "/pokemon/":
+			fmt.Fprint(w, `{"results": [{"name": "pikachu"}, {"name": "pidgey"}, {"name": "mr-mime"}]}`)
+		case "/pokemon-species/":
+			fmt.Fprint(w, `{"results": []}`)
+		case "/location-area/canalave-city-area/":
+			fmt.Fprint(w, `{"name": "canalave-city-area",
+				"pokemon_encounters": [{"pokemon": {"name": "tentacool"}}, {"pokemon": {"name": "staryu"}}]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestCatchWithoutBaseExperience(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/pokemon/":
+			fmt.Fprint(w, `{"results": [{"name": "eternatus-eternamax"}]}`)
+		case "/pokemon/eternatus-eternamax/":
+			fmt.Fprint(w, `{"name": "eternatus-eternamax", "base_experience": null}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	cfg, _, errOut := newTestConfig(t, server, "")
+	if err := cfg.registry.dispatch(context.Background(), cfg, "catch eternatus-eternamax"); err != nil {
+		t.Fatalf("unexpected error: %v, %s", err, errOut)
+	}
+	if _, ok := cfg.Pokedex["eternatus-eternamax"]; !ok {
+		t.Errorf("expected pokemon without base experience to be caught")
+	}
+}
+
+func TestRunBatch(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected int
+	}{
+		{
+			input:    "pokedex; pokedex\n# comment\n\npokedex",
+			expected: 0,
+		},
+		{
+			input:    "pokedex; unknown-command\npokedex",
+			expected: 1,
+		},
+		{
+			input:    "pokedex extra-argument",
+			expected: 1,
+		},
+		{
+			// exit останавливает выполнение, но ошибки до него учитываются
+			input:    "unknown-command; exit; pokedex",
+			expected: 1,
+		},
+	}
+
+	for _, c := range cases {
+		cfg, _, _ := newTestConfig(t, nil, c.input)
+		actual := cfg.registry.runBatch(context.Background(), cfg)
+		if actual != c.expected {
+			t.Errorf("runBatch(%q) = %d; want %d", c.input, actual, c.expected)
+		}
+	}
+}
+
+func TestDispatchExit(t *testing.T) {
+	cfg, out, _ := newTestConfig(t, nil, "")
+
+	err := cfg.registry.dispatch(context.Background(), cfg, "exit")
+	if !errors.Is(err, errExit) {
+		t.Errorf("expected errExit, got %v", err)
+	}
+	if !strings.Contains(out.String(), "Goodbye!") {
+		t.Errorf("expected goodbye message, got %q", out.String())
+	}
+}
+
+func TestDispatchUsage(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected string
+	}{
+		{input: "map now", expected: "usage: map"},
+		{input: "inspect", expected: "usage: inspect <pokemon>"},
+		{input: "catch pikachu bulbasaur", expected: "usage: catch <pokemon>"},
+		{input: "explore", expected: "usage: explore <location-area>"},
+		{input: "help map catch", expected: "usage: help [command]"},
+	}
+
+	for _, c := range cases {
+		cfg, _, errOut := newTestConfig(t, nil, "")
+
+		if err := cfg.registry.dispatch(context.Background(), cfg, c.input); err == nil {
+			t.Errorf("%q: expected usage error", c.input)
+		}
+		if !strings.Contains(errOut.String(), c.expected) {
+			t.Errorf("%q: expected %q, got %q", c.input, c.expected, errOut.String())
+		}
+	}
+}
+
+func TestHelp(t *testing.T) {
+	cfg, out, _ := newTestConfig(t, nil, "")
+
+	if err := cfg.registry.dispatch(context.Background(), cfg, "help"); err != nil {
+		t.Fatalf("help: %v", err)
+	}
+	// Команды выводятся в алфавитном порядке
+	text := out.String()
+	if strings.Index(text, "cache:") > strings.Index(text, "exit:") || strings.Index(text, "exit:") > strings.Index(text, "save:") {
+		t.Errorf("expected commands in alphabetical order, got %q", text)
+	}
+
+	out.Reset()
+	if err := cfg.registry.dispatch(context.Background(), cfg, "? dex"); err != nil {
+		t.Fatalf("help dex: %v", err)
+	}
+	if !strings.Contains(out.String(), "pokedex: View all caught pokemon") || !strings.Contains(out.String(), "Aliases: dex") {
+		t.Errorf("expected pokedex help, got %q", out.String())
+	}
+
+	if err := cfg.registry.dispatch(context.Background(), cfg, "help nope"); err == nil {
+		t.Errorf("expected error for unknown command")
+	}
+}
+
+func TestMapAndExplore(t *testing.T) {
+	cfg, out, errOut := newTestConfig(t, newTestServer(t), "map\nexplore canalave-city-area\n")
+
+	if status := cfg.registry.runBatch(context.Background(), cfg); status != 0 {
+		t.Fatalf("expected success, got status %d: %s", status, errOut.String())
+	}
+	for _, expected := range []string{" - canalave-city-area", "Exploring canalave-city-area...", " - staryu"} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("expected output to contain %q, got %q", expected, out.String())
+		}
+	}
+}
+
+func TestOutputJSON(t *testing.T) {
+	cfg, out, _ := newTestConfig(t, nil, "")
+	cfg.Pokedex = map[string]CaughtPokemon{"pikachu": {}, "bulbasaur": {}}
+
+	if err := cfg.registry.dispatch(context.Background(), cfg, "pokedex -o json"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var v pokedexView
+	if err := json.Unmarshal(out.Bytes(), &v); err != nil {
+		t.Fatalf("expected JSON output, got %q: %v", out.String(), err)
+	}
+	if strings.Join(v.Pokemon, ",") != "bulbasaur,pikachu" {
+		t.Errorf("unexpected pokemon: %v", v.Pokemon)
+	}
+
+	// Формат меняется только для одной команды
+	out.Reset()
+	if err := cfg.registry.dispatch(context.Background(), cfg, "pokedex"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(out.String(), "Your Pokedex:") {
+		t.Errorf("expected text output, got %q", out.String())
+	}
+
+	if err := cfg.registry.dispatch(context.Background(), cfg, "pokedex -o yaml"); err == nil {
+		t.Errorf("expected error for unknown output format")
+	}
+}
+
+func TestRunCommandInterrupt(t *testing.T) {
+	cfg, _, errOut := newTestConfig(t, nil, "")
+	interrupts := make(chan os.Signal)
+	cfg.registry.interrupts = interrupts
+
+	// Команда завершается только после отмены и release
+	release := make(chan struct{})
+	cfg.registry.register(cliCommand{
+		name: "slow",
+		callback: func(ctx context.Context, cfg *Config, parameters []string) error {
+			<-ctx.Done()
+			<-release
+			return ctx.Err()
+		},
+	})
+
+	// Первый Ctrl-C отменяет команду
+	go func() {
+		interrupts <- os.Interrupt
+		close(release)
+	}()
+	quit, err := cfg.registry.runCommand(cfg, "slow")
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if quit {
+		t.Errorf("first interrupt should not quit")
+	}
+	if !strings.Contains(errOut.String(), `Command "slow" cancelled`) {
+		t.Errorf("expected cancel message, got %q", errOut.String())
+	}
+
+	// Второй Ctrl-C до конца команды просит завершить работу
+	release = make(chan struct{})
+	go func() {
+		interrupts <- os.Interrupt
+		interrupts <- os.Interrupt
+		close(release)
+	}()
+	quit, _ = cfg.registry.runCommand(cfg, "slow")
+	if !quit {
+		t.Errorf("second interrupt should quit")
+	}
+}
+
+func TestRunBatchInterrupted(t *testing.T) {
+	cfg, out, _ := newTestConfig(t, nil, "pokedex\npokedex\n")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if status := cfg.registry.runBatch(ctx, cfg); status != interruptedStatus {
+		t.Errorf("expected status %d, got %d", interruptedStatus, status)
+	}
+	if out.Len() != 0 {
+		t.Errorf("expected no commands to run, got %q", out.String())
+	}
+}
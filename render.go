@@ -0,0 +1,224 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Форматы вывода команд
+const (
+	outputText = "text"
+	outputJSON = "json"
+)
+
+// view - результат команды. В текстовом режиме он печатает себя сам,
+// в режиме JSON кодируется целиком
+type view interface {
+	writeText(w io.Writer)
+}
+
+// render выводит результат команды в выбранном формате
+func render(cfg *Config, v view) error {
+	if cfg.output == outputJSON {
+		encoder := json.NewEncoder(cfg.registry.out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(v)
+	}
+
+	v.writeText(cfg.registry.out)
+	return nil
+}
+
+// parseOutputFlag вынимает из аргументов команды "-o json" или "--output json".
+// Возвращает оставшиеся аргументы и выбранный формат, пустой, если флага не было
+func parseOutputFlag(arguments []string) ([]string, string, error) {
+	rest := make([]string, 0, len(arguments))
+	format := ""
+	for i := 0; i < len(arguments); i++ {
+		if arguments[i] != "-o" && arguments[i] != "--output" {
+			rest = append(rest, arguments[i])
+			continue
+		}
+		if i+1 >= len(arguments) {
+			return nil, "", fmt.Errorf("%s requires a format: %s or %s", arguments[i], outputText, outputJSON)
+		}
+		i++
+		format = arguments[i]
+		if err := validateOutput(format); err != nil {
+			return nil, "", err
+		}
+	}
+	return rest, format, nil
+}
+
+// validateOutput проверяет название формата вывода
+func validateOutput(format string) error {
+	if format != outputText && format != outputJSON {
+		return fmt.Errorf("unknown output format %q, use %s or %s", format, outputText, outputJSON)
+	}
+	return nil
+}
+
+// Простое сообщение без данных
+type messageView struct {
+	Message string `json:"message"`
+}
+
+func (v messageView) writeText(w io.Writer) {
+	fmt.Fprintln(w, v.Message)
+}
+
+// Страница локаций для map и mapb
+type locationsView struct {
+	Locations []string `json:"locations"`
+	Next      string   `json:"next"`
+	Previous  string   `json:"previous"`
+}
+
+func (v locationsView) writeText(w io.Writer) {
+	fmt.Fprintln(w)
+	for _, location := range v.Locations {
+		fmt.Fprintf(w, " - %s\n", location)
+	}
+	fmt.Fprintln(w)
+}
+
+// Покемоны в локации для explore
+type exploreView struct {
+	Area    string   `json:"area"`
+	Pokemon []string `json:"pokemon"`
+}
+
+func (v exploreView) writeText(w io.Writer) {
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "Exploring %s...\n", v.Area)
+	fmt.Fprintln(w, "Found Pokemon:")
+	for _, pokemon := range v.Pokemon {
+		fmt.Fprintf(w, " - %s\n", pokemon)
+	}
+	fmt.Fprintln(w)
+}
+
+// Результат броска покебола для catch
+type catchView struct {
+	Pokemon string `json:"pokemon"`
+	Caught  bool   `json:"caught"`
+}
+
+func (v catchView) writeText(w io.Writer) {
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "Throwing a Pokeball at %s...\n", v.Pokemon)
+	if v.Caught {
+		fmt.Fprintf(w, "%s was caught!\n\n", v.Pokemon)
+	} else {
+		fmt.Fprintf(w, "%s escaped!\n\n", v.Pokemon)
+	}
+}
+
+// Подробности о пойманном покемоне для inspect
+type inspectView struct {
+	Name     string     `json:"name"`
+	CaughtAt time.Time  `json:"caught_at"`
+	Height   int        `json:"height"`
+	Weight   int        `json:"weight"`
+	Stats    []statView `json:"stats"`
+	Types    []string   `json:"types"`
+}
+
+type statView struct {
+	Name     string `json:"name"`
+	BaseStat int    `json:"base_stat"`
+}
+
+func (v inspectView) writeText(w io.Writer) {
+	fmt.Fprintf(w, "\nName: %s\n", v.Name)
+	fmt.Fprintf(w, "Caught: %s\n", v.CaughtAt.Format(time.DateTime))
+	fmt.Fprintf(w, "Height: %d\n", v.Height)
+	fmt.Fprintf(w, "Weight: %d\n", v.Weight)
+	fmt.Fprintln(w, "Stats:")
+	for _, stat := range v.Stats {
+		fmt.Fprintf(w, "  -%s: %d\n", stat.Name, stat.BaseStat)
+	}
+	fmt.Fprintln(w, "Types:")
+	for _, pokemonType := range v.Types {
+		fmt.Fprintf(w, "  -%s\n", pokemonType)
+	}
+	fmt.Fprintln(w)
+}
+
+// Список пойманных покемонов для pokedex
+type pokedexView struct {
+	Pokemon []string `json:"pokemon"`
+}
+
+func (v pokedexView) writeText(w io.Writer) {
+	if len(v.Pokemon) == 0 {
+		fmt.Fprintln(w, "You have not caught any pokemon yet.")
+		return
+	}
+
+	fmt.Fprintln(w, "Your Pokedex:")
+	for _, pokemon := range v.Pokemon {
+		fmt.Fprintf(w, "  - %s\n", pokemon)
+	}
+}
+
+// Список команд для help
+type helpView struct {
+	Commands []commandHelpView `json:"commands"`
+}
+
+func (v helpView) writeText(w io.Writer) {
+	fmt.Fprintf(w, "\nWelcome to the Pokedex!\n\n")
+	fmt.Fprintln(w, "Usage:")
+	for _, cmd := range v.Commands {
+		fmt.Fprintf(w, "%s: %s\n", cmd.Name, cmd.Description)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Type 'help <command>' for details about a command.")
+	fmt.Fprintln(w)
+}
+
+// Подробная справка по команде для help <command>
+type commandHelpView struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Usage       string   `json:"usage"`
+	Aliases     []string `json:"aliases"`
+	Examples    []string `json:"examples"`
+}
+
+func newCommandHelpView(cmd cliCommand) commandHelpView {
+	v := commandHelpView{
+		Name:        cmd.name,
+		Description: cmd.description,
+		Usage:       cmd.usage(),
+		Aliases:     cmd.aliases,
+		Examples:    cmd.examples,
+	}
+	if v.Aliases == nil {
+		v.Aliases = []string{}
+	}
+	if v.Examples == nil {
+		v.Examples = []string{}
+	}
+	return v
+}
+
+func (v commandHelpView) writeText(w io.Writer) {
+	fmt.Fprintf(w, "\n%s: %s\n\n", v.Name, v.Description)
+	fmt.Fprintf(w, "Usage: %s\n", v.Usage)
+	if len(v.Aliases) > 0 {
+		fmt.Fprintf(w, "Aliases: %s\n", strings.Join(v.Aliases, ", "))
+	}
+	if len(v.Examples) > 0 {
+		fmt.Fprintln(w, "Examples:")
+		for _, example := range v.Examples {
+			fmt.Fprintf(w, "  %s\n", example)
+		}
+	}
+	fmt.Fprintln(w)
+}
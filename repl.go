@@ -1,14 +1,14 @@
 ﻿package main
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"io"
 	"math/rand"
-	"net/http"
-	"os"
+	"sort"
 	"strings"
+	"time"
 
+	"github.com/CodeHunt7/go-pokedex/internal/pokeapi"
 	"github.com/CodeHunt7/go-pokedex/internal/pokecache"
 )
 
@@ -17,368 +17,38 @@ const ThrowingDifficulty = 40
 
 // Структура для хранения состояния ссылок на API
 type Config struct {
-    Next      string
-    Previous  string
-    pokeCache pokecache.Cache
-    Pokedex   map[string]PokemonResponse
+    Next          string
+    Previous      string
+    pokeapiClient *pokeapi.Client
+    pokeCache     *pokecache.Cache
+    Pokedex       map[string]CaughtPokemon
+
+    // Локации, которые показывали map и mapb, для автодополнения
+    seenAreas     map[string]bool
+
+    // Активный профиль и его слот сохранения
+    profile       string
+    saveDir       string
+    savePath      string
+    autosave      bool
+
+    // Пользовательский конфиг-файл
+    settingsPath  string
+    settings      UserSettings
+
+    // Команды с их вводом-выводом и формат вывода
+    registry      *registry
+    output        string
+
+    // Режим без сети и снимок API для него
+    offline       bool
+    snapshotPath  string
 }
 
-// Структура для распаковки JSON ответа от PokeAPI по списку локаций
-type LocationAreaResponse struct {
-	Count    int    `json:"count"`
-	Next     string `json:"next"`
-	Previous any    `json:"previous"`
-	Results  []struct {
-		Name string `json:"name"`
-		URL  string `json:"url"`
-	} `json:"results"`
-}
-
-// Структура для распаковки JSON ответа от PokeAPI по конкретной локации
-type ConcreteLocationResponce struct {
-	EncounterMethodRates []struct {
-		EncounterMethod struct {
-			Name string `json:"name"`
-			URL  string `json:"url"`
-		} `json:"encounter_method"`
-		VersionDetails []struct {
-			Rate    int `json:"rate"`
-			Version struct {
-				Name string `json:"name"`
-				URL  string `json:"url"`
-			} `json:"version"`
-		} `json:"version_details"`
-	} `json:"encounter_method_rates"`
-	GameIndex int `json:"game_index"`
-	ID        int `json:"id"`
-	Location  struct {
-		Name string `json:"name"`
-		URL  string `json:"url"`
-	} `json:"location"`
-	Name  string `json:"name"`
-	Names []struct {
-		Language struct {
-			Name string `json:"name"`
-			URL  string `json:"url"`
-		} `json:"language"`
-		Name string `json:"name"`
-	} `json:"names"`
-	PokemonEncounters []struct {
-		Pokemon struct {
-			Name string `json:"name"`
-			URL  string `json:"url"`
-		} `json:"pokemon"`
-		VersionDetails []struct {
-			EncounterDetails []struct {
-				Chance          int           `json:"chance"`
-				ConditionValues []interface{} `json:"condition_values"`
-				MaxLevel        int           `json:"max_level"`
-				Method          struct {
-					Name string `json:"name"`
-					URL  string `json:"url"`
-				} `json:"method"`
-				MinLevel int `json:"min_level"`
-			} `json:"encounter_details"`
-			MaxChance int `json:"max_chance"`
-			Version   struct {
-				Name string `json:"name"`
-				URL  string `json:"url"`
-			} `json:"version"`
-		} `json:"version_details"`
-	} `json:"pokemon_encounters"`
-}
-
-// Структура для распаковки JSON ответа от PokeAPI по конкретному покемону
-type PokemonResponse struct {
-	Abilities []struct {
-		Ability struct {   
-			Name string `json:"name"`
-			URL  string `json:"url"`
-		} `json:"ability"`
-		IsHidden bool `json:"is_hidden"`
-		Slot     int  `json:"slot"`
-	} `json:"abilities"`
-	BaseExperience int `json:"base_experience"`
-	Cries          struct {
-		Latest string `json:"latest"`
-		Legacy string `json:"legacy"`
-	} `json:"cries"`
-	Forms []struct {
-		Name string `json:"name"`
-		URL  string `json:"url"`
-	} `json:"forms"`
-	GameIndices []struct {
-		GameIndex int `json:"game_index"`
-		Version   struct {
-			Name string `json:"name"`
-			URL  string `json:"url"`
-		} `json:"version"`
-	} `json:"game_indices"`
-	Height    int `json:"height"`
-	HeldItems []struct {
-		Item struct {
-			Name string `json:"name"`
-			URL  string `json:"url"`
-		} `json:"item"`
-		VersionDetails []struct {
-			Rarity  int `json:"rarity"`
-			Version struct {
-				Name string `json:"name"`
-				URL  string `json:"url"`
-			} `json:"version"`
-		} `json:"version_details"`
-	} `json:"held_items"`
-	ID                     int    `json:"id"`
-	IsDefault              bool   `json:"is_default"`
-	LocationAreaEncounters string `json:"location_area_encounters"`
-	Moves                  []struct {
-		Move struct {
-			Name string `json:"name"`
-			URL  string `json:"url"`
-		} `json:"move"`
-		VersionGroupDetails []struct {
-			LevelLearnedAt  int `json:"level_learned_at"`
-			MoveLearnMethod struct {
-				Name string `json:"name"`
-				URL  string `json:"url"`
-			} `json:"move_learn_method"`
-			Order        interface{} `json:"order"`
-			VersionGroup struct {
-				Name string `json:"name"`
-				URL  string `json:"url"`
-			} `json:"version_group"`
-		} `json:"version_group_details"`
-	} `json:"moves"`
-	Name          string `json:"name"`
-	Order         int    `json:"order"`
-	PastAbilities []struct {
-		Abilities []struct {
-			Ability  interface{} `json:"ability"`
-			IsHidden bool        `json:"is_hidden"`
-			Slot     int         `json:"slot"`
-		} `json:"abilities"`
-		Generation struct {
-			Name string `json:"name"`
-			URL  string `json:"url"`
-		} `json:"generation"`
-	} `json:"past_abilities"`
-	PastTypes []interface{} `json:"past_types"`
-	Species   struct {
-		Name string `json:"name"`
-		URL  string `json:"url"`
-	} `json:"species"`
-	Sprites struct {
-		BackDefault      string `json:"back_default"`
-		BackFemale       string `json:"back_female"`
-		BackShiny        string `json:"back_shiny"`
-		BackShinyFemale  string `json:"back_shiny_female"`
-		FrontDefault     string `json:"front_default"`
-		FrontFemale      string `json:"front_female"`
-		FrontShiny       string `json:"front_shiny"`
-		FrontShinyFemale string `json:"front_shiny_female"`
-		Other            struct {
-			DreamWorld struct {
-				FrontDefault string      `json:"front_default"`
-				FrontFemale  interface{} `json:"front_female"`
-			} `json:"dream_world"`
-			Home struct {
-				FrontDefault     string `json:"front_default"`
-				FrontFemale      string `json:"front_female"`
-				FrontShiny       string `json:"front_shiny"`
-				FrontShinyFemale string `json:"front_shiny_female"`
-			} `json:"home"`
-			OfficialArtwork struct {
-				FrontDefault string `json:"front_default"`
-				FrontShiny   string `json:"front_shiny"`
-			} `json:"official-artwork"`
-			Showdown struct {
-				BackDefault      string      `json:"back_default"`
-				BackFemale       string      `json:"back_female"`
-				BackShiny        string      `json:"back_shiny"`
-				BackShinyFemale  interface{} `json:"back_shiny_female"`
-				FrontDefault     string      `json:"front_default"`
-				FrontFemale      string      `json:"front_female"`
-				FrontShiny       string      `json:"front_shiny"`
-				FrontShinyFemale string      `json:"front_shiny_female"`
-			} `json:"showdown"`
-		} `json:"other"`
-		Versions struct {
-			GenerationI struct {
-				RedBlue struct {
-					BackDefault      string `json:"back_default"`
-					BackGray         string `json:"back_gray"`
-					BackTransparent  string `json:"back_transparent"`
-					FrontDefault     string `json:"front_default"`
-					FrontGray        string `json:"front_gray"`
-					FrontTransparent string `json:"front_transparent"`
-				} `json:"red-blue"`
-				Yellow struct {
-					BackDefault      string `json:"back_default"`
-					BackGray         string `json:"back_gray"`
-					BackTransparent  string `json:"back_transparent"`
-					FrontDefault     string `json:"front_default"`
-					FrontGray        string `json:"front_gray"`
-					FrontTransparent string `json:"front_transparent"`
-				} `json:"yellow"`
-			} `json:"generation-i"`
-			GenerationIi struct {
-				Crystal struct {
-					BackDefault           string `json:"back_default"`
-					BackShiny             string `json:"back_shiny"`
-					BackShinyTransparent  string `json:"back_shiny_transparent"`
-					BackTransparent       string `json:"back_transparent"`
-					FrontDefault          string `json:"front_default"`
-					FrontShiny            string `json:"front_shiny"`
-					FrontShinyTransparent string `json:"front_shiny_transparent"`
-					FrontTransparent      string `json:"front_transparent"`
-				} `json:"crystal"`
-				Gold struct {
-					BackDefault      string `json:"back_default"`
-					BackShiny        string `json:"back_shiny"`
-					FrontDefault     string `json:"front_default"`
-					FrontShiny       string `json:"front_shiny"`
-					FrontTransparent string `json:"front_transparent"`
-				} `json:"gold"`
-				Silver struct {
-					BackDefault      string `json:"back_default"`
-					BackShiny        string `json:"back_shiny"`
-					FrontDefault     string `json:"front_default"`
-					FrontShiny       string `json:"front_shiny"`
-					FrontTransparent string `json:"front_transparent"`
-				} `json:"silver"`
-			} `json:"generation-ii"`
-			GenerationIii struct {
-				Emerald struct {
-					FrontDefault string `json:"front_default"`
-					FrontShiny   string `json:"front_shiny"`
-				} `json:"emerald"`
-				FireredLeafgreen struct {
-					BackDefault  string `json:"back_default"`
-					BackShiny    string `json:"back_shiny"`
-					FrontDefault string `json:"front_default"`
-					FrontShiny   string `json:"front_shiny"`
-				} `json:"firered-leafgreen"`
-				RubySapphire struct {
-					BackDefault  string `json:"back_default"`
-					BackShiny    string `json:"back_shiny"`
-					FrontDefault string `json:"front_default"`
-					FrontShiny   string `json:"front_shiny"`
-				} `json:"ruby-sapphire"`
-			} `json:"generation-iii"`
-			GenerationIv struct {
-				DiamondPearl struct {
-					BackDefault      string `json:"back_default"`
-					BackFemale       string `json:"back_female"`
-					BackShiny        string `json:"back_shiny"`
-					BackShinyFemale  string `json:"back_shiny_female"`
-					FrontDefault     string `json:"front_default"`
-					FrontFemale      string `json:"front_female"`
-					FrontShiny       string `json:"front_shiny"`
-					FrontShinyFemale string `json:"front_shiny_female"`
-				} `json:"diamond-pearl"`
-				HeartgoldSoulsilver struct {
-					BackDefault      string `json:"back_default"`
-					BackFemale       string `json:"back_female"`
-					BackShiny        string `json:"back_shiny"`
-					BackShinyFemale  string `json:"back_shiny_female"`
-					FrontDefault     string `json:"front_default"`
-					FrontFemale      string `json:"front_female"`
-					FrontShiny       string `json:"front_shiny"`
-					FrontShinyFemale string `json:"front_shiny_female"`
-				} `json:"heartgold-soulsilver"`
-				Platinum struct {
-					BackDefault      string `json:"back_default"`
-					BackFemale       string `json:"back_female"`
-					BackShiny        string `json:"back_shiny"`
-					BackShinyFemale  string `json:"back_shiny_female"`
-					FrontDefault     string `json:"front_default"`
-					FrontFemale      string `json:"front_female"`
-					FrontShiny       string `json:"front_shiny"`
-					FrontShinyFemale string `json:"front_shiny_female"`
-				} `json:"platinum"`
-			} `json:"generation-iv"`
-			GenerationIx struct {
-				ScarletViolet struct {
-					FrontDefault string      `json:"front_default"`
-					FrontFemale  interface{} `json:"front_female"`
-				} `json:"scarlet-violet"`
-			} `json:"generation-ix"`
-			GenerationV struct {
-				BlackWhite struct {
-					Animated struct {
-						BackDefault      string `json:"back_default"`
-						BackFemale       string `json:"back_female"`
-						BackShiny        string `json:"back_shiny"`
-						BackShinyFemale  string `json:"back_shiny_female"`
-						FrontDefault     string `json:"front_default"`
-						FrontFemale      string `json:"front_female"`
-						FrontShiny       string `json:"front_shiny"`
-						FrontShinyFemale string `json:"front_shiny_female"`
-					} `json:"animated"`
-					BackDefault      string `json:"back_default"`
-					BackFemale       string `json:"back_female"`
-					BackShiny        string `json:"back_shiny"`
-					BackShinyFemale  string `json:"back_shiny_female"`
-					FrontDefault     string `json:"front_default"`
-					FrontFemale      string `json:"front_female"`
-					FrontShiny       string `json:"front_shiny"`
-					FrontShinyFemale string `json:"front_shiny_female"`
-				} `json:"black-white"`
-			} `json:"generation-v"`
-			GenerationVi struct {
-				OmegarubyAlphasapphire struct {
-					FrontDefault     string `json:"front_default"`
-					FrontFemale      string `json:"front_female"`
-					FrontShiny       string `json:"front_shiny"`
-					FrontShinyFemale string `json:"front_shiny_female"`
-				} `json:"omegaruby-alphasapphire"`
-				XY struct {
-					FrontDefault     string `json:"front_default"`
-					FrontFemale      string `json:"front_female"`
-					FrontShiny       string `json:"front_shiny"`
-					FrontShinyFemale string `json:"front_shiny_female"`
-				} `json:"x-y"`
-			} `json:"generation-vi"`
-			GenerationVii struct {
-				Icons struct {
-					FrontDefault string      `json:"front_default"`
-					FrontFemale  interface{} `json:"front_female"`
-				} `json:"icons"`
-				UltraSunUltraMoon struct {
-					FrontDefault     string `json:"front_default"`
-					FrontFemale      string `json:"front_female"`
-					FrontShiny       string `json:"front_shiny"`
-					FrontShinyFemale string `json:"front_shiny_female"`
-				} `json:"ultra-sun-ultra-moon"`
-			} `json:"generation-vii"`
-			GenerationViii struct {
-				BrilliantDiamondShiningPearl struct {
-					FrontDefault string      `json:"front_default"`
-					FrontFemale  interface{} `json:"front_female"`
-				} `json:"brilliant-diamond-shining-pearl"`
-				Icons struct {
-					FrontDefault string `json:"front_default"`
-					FrontFemale  string `json:"front_female"`
-				} `json:"icons"`
-			} `json:"generation-viii"`
-		} `json:"versions"`
-	} `json:"sprites"`
-	Stats []struct {
-		BaseStat int `json:"base_stat"`
-		Effort   int `json:"effort"`
-		Stat     struct {
-			Name string `json:"name"`
-			URL  string `json:"url"`
-		} `json:"stat"`
-	} `json:"stats"`
-	Types []struct {
-		Slot int `json:"slot"`
-		Type struct {
-			Name string `json:"name"`
-			URL  string `json:"url"`
-		} `json:"type"`
-	} `json:"types"`
-	Weight int `json:"weight"`
+// Структура для пойманного покемона
+type CaughtPokemon struct {
+	Pokemon  pokeapi.PokemonResponse `json:"pokemon"`
+	CaughtAt time.Time               `json:"caught_at"`
 }
 
 func cleanInput(text string) []string {
@@ -396,305 +66,183 @@ func cleanInput(text string) []string {
     return output
 }
 
-func commandExit(cfg *Config, parameters []string) error {
-    fmt.Println("Closing the Pokedex... Goodbye!")
-    os.Exit(0)
-    return nil
+func commandExit(ctx context.Context, cfg *Config, parameters []string) error {
+    // Прогресс сохраняется при выходе из цикла команд
+    fmt.Fprintln(cfg.registry.out, "Closing the Pokedex... Goodbye!")
+    return errExit
 }
 
-func commandHelp(cfg *Config, parameters []string) error {
-    fmt.Printf("\nWelcome to the Pokedex!\n\n")
-    fmt.Println("Usage:")
-    for _, cmd := range commands {
-        fmt.Printf("%s: %s\n", cmd.name, cmd.description)
-    }
-    fmt.Println()
-    return nil
-}
-
-func commandMap(cfg *Config, parameters []string) error {
-    // Инициализируем ссылки
-    NextURL := cfg.Next
-    if NextURL == "" { 
-        NextURL = "https://pokeapi.co/api/v2/location-area/?offset=0&limit=20"
-    }
-
-    // Создаем переменные для тела ответа и ошибки
-    var body []byte
-    var err error
+func commandHelp(ctx context.Context, cfg *Config, parameters []string) error {
+	// Подробная справка по одной команде
+	if len(parameters) == 1 {
+		cmd, exists := cfg.registry.lookup(parameters[0])
+		if !exists {
+			return fmt.Errorf("unknown command %q", parameters[0])
+		}
+		return render(cfg, newCommandHelpView(cmd))
+	}
 
-    // Проверяем есть ли ответ в кеше
-    cachedResponse, inCache := cfg.pokeCache.Get(NextURL)
-    
-    if !inCache { // В кеше нет, делаем запрос
-        // Получаем JSON ответ от PokeAPI
-        res, err := http.Get(NextURL)
-        if err != nil { 
-            return err
-        }
-        body, err = io.ReadAll(res.Body)
-        res.Body.Close()
-        if res.StatusCode > 299 {
-            return fmt.Errorf("response failed with status code: %d and\nbody: %s", res.StatusCode, body)
-        }
-        if err != nil {
-            return err
-        }
-        //fmt.Println(body)
-        //fmt.Println("FROM API!")
-    } else { // В кеше есть, используем его
-        body = cachedResponse
-        //fmt.Println("FROM CACHE!")
-    }
-    
-    // Распаковываем JSON в стуктуру
-    var locations LocationAreaResponse
-    err = json.Unmarshal(body, &locations)
-    if err != nil {
-        return err
-    }
+	// Список всех команд по алфавиту
+	v := helpView{Commands: []commandHelpView{}}
+	for _, cmd := range cfg.registry.sorted() {
+		v.Commands = append(v.Commands, newCommandHelpView(cmd))
+	}
+	return render(cfg, v)
+}
 
-    // Обновляем глобальный конфиг и кеш
-    cfg.Next = locations.Next
-    if locations.Previous != nil {
-        cfg.Previous = locations.Previous.(string)
-    } else {
-        cfg.Previous = ""
-    }
-    cfg.pokeCache.Add(NextURL, body)
+func commandMap(ctx context.Context, cfg *Config, parameters []string) error {
+	// Получаем страницу локаций, пустая ссылка означает первую страницу
+	locations, err := cfg.pokeapiClient.ListLocationAreas(ctx, cfg.Next)
+	if err != nil {
+		return err
+	}
 
-    // Выводим ответ в консоль
-    fmt.Println()
-    for _, location := range locations.Results {
-        fmt.Printf(" - %s\n", location.Name)
-    }
-    fmt.Println()
+	// Обновляем глобальный конфиг
+	updatePagination(cfg, locations)
 
-    return nil
+	// Выводим ответ в консоль
+	return render(cfg, newLocationsView(cfg, locations))
 }
 
-func commandMapBack(cfg *Config, parameters []string) error {
-    // Инициализируем ссылки
-    PrevURL := cfg.Previous
-    if PrevURL == "" {
-        fmt.Println("No previous locations available.")
-        return nil
-    }
-
-    // Создаем переменные для тела ответа и ошибки
-    var body []byte
-    var err error
+func commandMapBack(ctx context.Context, cfg *Config, parameters []string) error {
+	// Инициализируем ссылки
+	if cfg.Previous == "" {
+		return render(cfg, messageView{"No previous locations available."})
+	}
 
-    // Проверяем есть ли в кеше
-    cachedResponse, inCache := cfg.pokeCache.Get(PrevURL)
-    
-    if !inCache { // В кеше нет, делаем запрос
-        // Получаем JSON ответ от PokeAPI
-        res, err := http.Get(PrevURL)
-        if err != nil { 
-            return err
-        }
-        body, err = io.ReadAll(res.Body)
-        res.Body.Close()
-        if res.StatusCode > 299 {
-            return fmt.Errorf("response failed with status code: %d and\nbody: %s", res.StatusCode, body)
-        }
-        if err != nil {
-            return err
-        }
-        // fmt.Println("FROM API!")
-        // fmt.Println(PrevURL)
-    } else { // В кеще есть, используем его
-        body = cachedResponse
-        // fmt.Println("FROM CACHE!")
-    }
+	// Получаем предыдущую страницу локаций
+	locations, err := cfg.pokeapiClient.ListLocationAreas(ctx, cfg.Previous)
+	if err != nil {
+		return err
+	}
 
-    // Распаковываем JSON в стуктуру
-    var locations LocationAreaResponse
-    err = json.Unmarshal(body, &locations)
-    if err != nil {
-        return err
-    }
+	// Обновляем глобальный конфиг
+	updatePagination(cfg, locations)
 
-    // Обновляем глобальный конфиг
-    cfg.Next = locations.Next
-    if locations.Previous != nil {
-        cfg.Previous = locations.Previous.(string)
-    } else {
-        cfg.Previous = ""
-    }
-    cfg.pokeCache.Add(PrevURL, body)
+	// Выводим ответ в консоль
+	return render(cfg, newLocationsView(cfg, locations))
+}
 
-    // Выводим ответ в консоль
-    fmt.Println()
-    for _, location := range locations.Results {
-        fmt.Printf(" - %s\n", location.Name)
-    }
-    fmt.Println()
+// updatePagination сохраняет ссылки на соседние страницы локаций
+// и запоминает показанные локации
+func updatePagination(cfg *Config, locations pokeapi.LocationAreaResponse) {
+	cfg.Next = locations.Next
+	if locations.Previous != nil {
+		cfg.Previous = locations.Previous.(string)
+	} else {
+		cfg.Previous = ""
+	}
 
-    return nil
+	if cfg.seenAreas == nil {
+		cfg.seenAreas = make(map[string]bool)
+	}
+	for _, location := range locations.Results {
+		cfg.seenAreas[location.Name] = true
+	}
 }
 
-func commandExplore(cfg *Config, parameters []string) error {
-    // инициализируем ссылку
-    if len(parameters) == 0 {
-        fmt.Println("Please provide a location area name to explore.")
-        return nil
-    }
-    exploreURL := fmt.Sprintf("https://pokeapi.co/api/v2/location-area/%s/", parameters[0])
-
-    // Создаем переменные для тела ответа и ошибки
-    var body []byte
-    var err error
-
-    // проверяем есть ли в кеше
-    cachedResponse, inCache := cfg.pokeCache.Get(exploreURL)
-    if !inCache { // В кеше нет, делаем запрос
-        // Получаем JSON ответ от PokeAPI
-        res, err := http.Get(exploreURL)
-        if err != nil { 
-            return err
-        }
-        body, err = io.ReadAll(res.Body)
-        res.Body.Close()
-        if res.StatusCode > 299 {
-            return fmt.Errorf("response failed with status code: %d and\nbody: %s", res.StatusCode, body)
-        }
-        if err != nil {
-            return err
-        }
-    } else { // В кеще есть, используем его
-        body = cachedResponse
-    }
-
-    // Распаковываем JSON в стуктуру
-    var locationInfo ConcreteLocationResponce
-    err = json.Unmarshal(body, &locationInfo)
-    if err != nil {
-        return err
-    }
+// newLocationsView собирает названия локаций со страницы
+func newLocationsView(cfg *Config, locations pokeapi.LocationAreaResponse) locationsView {
+	v := locationsView{
+		Locations: make([]string, 0, len(locations.Results)),
+		Next:      cfg.Next,
+		Previous:  cfg.Previous,
+	}
+	for _, location := range locations.Results {
+		v.Locations = append(v.Locations, location.Name)
+	}
+	return v
+}
 
-    // Обновляем глобальный кеш в конфиге
-    cfg.pokeCache.Add(exploreURL, body)
+func commandExplore(ctx context.Context, cfg *Config, parameters []string) error {
+	// Сверяем название с полным списком локаций, чтобы не ходить в API с опечаткой
+	if !knownName(parameters[0], areaIndex(ctx, cfg)) {
+		return unknownAreaError(parameters[0], areaNames(ctx, cfg))
+	}
 
-    // Выводим ответ в консоль
-    fmt.Println()
-    fmt.Printf("Exploring %s...\n", parameters[0])
-    fmt.Println("Found Pokemon:")
-    for _, pokemonInfo := range locationInfo.PokemonEncounters {
-        fmt.Printf(" - %s\n", pokemonInfo.Pokemon.Name)
-    }
-    fmt.Println()
+	// Получаем информацию о локации
+	locationInfo, err := cfg.pokeapiClient.GetLocationArea(ctx, parameters[0])
+	if err != nil {
+		return err
+	}
 
-    return nil
+	// Выводим ответ в консоль
+	v := exploreView{
+		Area:    parameters[0],
+		Pokemon: make([]string, 0, len(locationInfo.PokemonEncounters)),
+	}
+	for _, pokemonInfo := range locationInfo.PokemonEncounters {
+		v.Pokemon = append(v.Pokemon, pokemonInfo.Pokemon.Name)
+	}
+	return render(cfg, v)
 }
 
-func commandCatch(cfg *Config, parameters []string) error {
-    // инициализируем ссылку
-    if len(parameters) == 0 {
-        fmt.Println("Please provide a name of the pokemon to catch.")
-        return nil
-    }
-    catchURL := fmt.Sprintf("https://pokeapi.co/api/v2/pokemon/%s/", parameters[0])
-
-    // Создаем переменные для тела ответа и ошибки
-    var body []byte
-    var err error
-
-    // проверяем есть ли в кеше
-    cachedResponse, inCache := cfg.pokeCache.Get(catchURL)
-    if !inCache { // В кеше нет, делаем запрос
-        // Получаем JSON ответ от PokeAPI
-        res, err := http.Get(catchURL)
-        if err != nil { 
-            return err
-        }
-        body, err = io.ReadAll(res.Body)
-        res.Body.Close()
-
-        // поверяем, что покемон существует
-        if res.StatusCode == 404 {
-            fmt.Printf("%s is not a valid pokemon name\n", parameters[0])
-            return nil
-        }
-
-        if res.StatusCode > 299 {
-            return fmt.Errorf("response failed with status code: %d and\nbody: %s", res.StatusCode, body)
-        }
-        if err != nil {
-            return err
-        }
-    } else { // В кеще есть, используем его
-        body = cachedResponse
-    }
-    
-    // Распаковываем JSON в стуктуру
-    var pokemonInfo PokemonResponse
-    err = json.Unmarshal(body, &pokemonInfo)
-    if err != nil {
-        return err
-    }
+func commandCatch(ctx context.Context, cfg *Config, parameters []string) error {
+	// Сверяем имя с полным списком покемонов, чтобы не ходить в API с опечаткой
+	names := pokemonNames(ctx, cfg)
+	if !knownName(parameters[0], names) {
+		return unknownPokemonError(parameters[0], names)
+	}
 
-    // Считаем шансы поймать и Выводим ответ в консоль
-    fmt.Println()
-    fmt.Printf("Throwing a Pokeball at %s...\n", parameters[0])
-    throwResult := rand.Intn(pokemonInfo.BaseExperience)
-    
-    if throwResult < ThrowingDifficulty { // поймал
-        fmt.Printf("%s was caught!\n\n", pokemonInfo.Name)
-        cfg.Pokedex[pokemonInfo.Name] = pokemonInfo
-        //fmt.Printf("Deb: \n%d - base exp \n%d - throw res \n%d - defficulty\n", pokemonInfo.BaseExperience, throwResult, ThrowingDifficulty)
-    } else { // не поймал
-        fmt.Printf("%s escaped!\n\n", pokemonInfo.Name)
-        //fmt.Printf("Deb: \n%d - base exp \n%d - throw res \n%d - defficulty\n", pokemonInfo.BaseExperience, throwResult, ThrowingDifficulty)
-    }
+	// Получаем информацию о покемоне
+	pokemonInfo, err := cfg.pokeapiClient.GetPokemon(ctx, parameters[0])
+	if err != nil {
+		return err
+	}
 
-    // Обновляем глобальный кеш в конфиге
-    cfg.pokeCache.Add(catchURL, body)
+	// Считаем шансы поймать. У части покемонов в API нет опыта,
+	// их считаем самыми легкими
+	throwResult := rand.Intn(max(pokemonInfo.BaseExperience, 1))
+	caught := throwResult < ThrowingDifficulty
 
-    return nil
+	if caught { // поймал
+		cfg.Pokedex[pokemonInfo.Name] = CaughtPokemon{
+			Pokemon:  pokemonInfo,
+			CaughtAt: time.Now(),
+		}
+	}
+
+	// Выводим ответ в консоль
+	return render(cfg, catchView{Pokemon: pokemonInfo.Name, Caught: caught})
 }
 
-func commandInspect(cfg *Config, parameters []string) error {
+func commandInspect(ctx context.Context, cfg *Config, parameters []string) error {
 	
 	// Проверяем, пойман ли этот покемон
-	thisPokemon, exists := cfg.Pokedex[parameters[0]];
+	caught, exists := cfg.Pokedex[parameters[0]]
 	
 	if !exists { // не пойман
-		fmt.Printf("you have not caught that pokemon\n")
-		return nil
+		return render(cfg, messageView{didYouMean("you have not caught that pokemon", suggest(parameters[0], caughtNames(cfg)))})
 	}
 	
 	// пойман, значит выдаем инфу
-	fmt.Printf("\nName: %s\n", thisPokemon.Name)
-	fmt.Printf("Height: %d\n", thisPokemon.Height)
-	fmt.Printf("Weight: %d\n", thisPokemon.Weight)
-	fmt.Println("Stats:")
+	thisPokemon := caught.Pokemon
+	v := inspectView{
+		Name:     thisPokemon.Name,
+		CaughtAt: caught.CaughtAt,
+		Height:   thisPokemon.Height,
+		Weight:   thisPokemon.Weight,
+		Stats:    []statView{},
+		Types:    []string{},
+	}
 	for _, thisStat := range thisPokemon.Stats {
-		fmt.Printf("  -%s: %d\n", thisStat.Stat.Name, thisStat.BaseStat)
+		v.Stats = append(v.Stats, statView{Name: thisStat.Stat.Name, BaseStat: thisStat.BaseStat})
 	}
-	fmt.Println("Types:")
 	for _, thisType := range thisPokemon.Types {
-		fmt.Printf("  -%s\n", thisType.Type.Name)
+		v.Types = append(v.Types, thisType.Type.Name)
 	}
-	fmt.Println()
 
-	return nil
+	return render(cfg, v)
 }
 
-func commandPokedex(cfg *Config, parameters []string) error {
+func commandPokedex(ctx context.Context, cfg *Config, parameters []string) error {
 
-	// Проверяем, есть ли пойманные покемоны
-	if len(cfg.Pokedex) == 0 {
-		fmt.Println("You have not caught any pokemon yet.")
-		return nil
-	}
-	
-	// Выводим всех пойманных покемонов
-	fmt.Println("Your Pokedex:")
+	// Собираем всех пойманных покемонов по алфавиту
+	v := pokedexView{Pokemon: make([]string, 0, len(cfg.Pokedex))}
 	for pokenomName := range cfg.Pokedex {
-		fmt.Printf("  - %s\n", pokenomName) 
+		v.Pokemon = append(v.Pokemon, pokenomName)
 	}
-	
-	return nil
-}
\ No newline at end of file
+	sort.Strings(v.Pokemon)
+
+	return render(cfg, v)
+}
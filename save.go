@@ -0,0 +1,200 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Текущая версия формата файла сохранения.
+// При изменении формата увеличиваем версию и добавляем шаг в migrateSave
+const saveVersion = 2
+
+// Структура файла сохранения.
+// Страницы карты хранятся относительно адреса API,
+// например location-area/?offset=20&limit=20
+type SaveFile struct {
+	Version  int                      `json:"version"`
+	SavedAt  time.Time                `json:"saved_at"`
+	Pokedex  map[string]CaughtPokemon `json:"pokedex"`
+	Next     string                   `json:"next"`
+	Previous string                   `json:"previous"`
+}
+
+// defaultSavePath возвращает путь к файлу сохранения по умолчанию
+func defaultSavePath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "pokedex", "save.json")
+}
+
+func commandSave(ctx context.Context, cfg *Config, parameters []string) error {
+	path := cfg.savePath
+	if len(parameters) > 0 {
+		path = parameters[0]
+	}
+
+	if err := saveGame(cfg, path); err != nil {
+		return err
+	}
+	return render(cfg, messageView{fmt.Sprintf("Saved %d pokemon to %s", len(cfg.Pokedex), path)})
+}
+
+func commandLoad(ctx context.Context, cfg *Config, parameters []string) error {
+	path := cfg.savePath
+	if len(parameters) > 0 {
+		path = parameters[0]
+	}
+
+	found, err := loadGame(cfg, path)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return render(cfg, messageView{fmt.Sprintf("No save file found at %s", path)})
+	}
+	return render(cfg, messageView{fmt.Sprintf("Loaded %d pokemon from %s", len(cfg.Pokedex), path)})
+}
+
+// saveGame записывает пойманных покемонов и страницу карты в файл
+func saveGame(cfg *Config, path string) error {
+	if path == "" {
+		return errors.New("no save file path")
+	}
+
+	base := cfg.pokeapiClient.BaseURL()
+	data, err := json.MarshalIndent(SaveFile{
+		Version:  saveVersion,
+		SavedAt:  time.Now(),
+		Pokedex:  cfg.Pokedex,
+		Next:     cursorPath(base, cfg.Next),
+		Previous: cursorPath(base, cfg.Previous),
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data)
+}
+
+// loadGame читает файл сохранения в конфиг.
+// found равен false, если файла еще нет
+func loadGame(cfg *Config, path string) (found bool, err error) {
+	if path == "" {
+		return false, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	save, err := migrateSave(data)
+	if err != nil {
+		return false, fmt.Errorf("read %s: %w", path, err)
+	}
+
+	cfg.Pokedex = save.Pokedex
+	if cfg.Pokedex == nil {
+		cfg.Pokedex = make(map[string]CaughtPokemon)
+	}
+	base := cfg.pokeapiClient.BaseURL()
+	cfg.Next = cursorLink(base, save.Next)
+	cfg.Previous = cursorLink(base, save.Previous)
+
+	return true, nil
+}
+
+// cursorPath переводит ссылку на страницу карты в путь относительно адреса API,
+// чтобы после смены адреса карта листалась дальше с той же страницы.
+// Ссылки на другой адрес не сохраняются
+func cursorPath(base, link string) string {
+	path, ok := strings.CutPrefix(link, base)
+	if !ok {
+		return ""
+	}
+	return path
+}
+
+// cursorLink собирает ссылку на страницу карты из пути относительно адреса API.
+// Целые ссылки из сохранений версии 1 подходят, только если ведут на этот же адрес
+func cursorLink(base, path string) string {
+	if path == "" {
+		return ""
+	}
+	if strings.Contains(path, "://") {
+		if !strings.HasPrefix(path, base) {
+			return ""
+		}
+		return path
+	}
+	return base + path
+}
+
+// migrateSave разбирает файл сохранения любой поддерживаемой версии
+// и приводит его к текущему формату
+func migrateSave(data []byte) (SaveFile, error) {
+	var header struct {
+		Version int `json:"version"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return SaveFile{}, err
+	}
+
+	switch {
+	case header.Version < 1:
+		return SaveFile{}, fmt.Errorf("unknown save file version %d", header.Version)
+	case header.Version > saveVersion:
+		return SaveFile{}, fmt.Errorf("save file version %d is newer than supported version %d", header.Version, saveVersion)
+	}
+
+	var save SaveFile
+	if err := json.Unmarshal(data, &save); err != nil {
+		return SaveFile{}, err
+	}
+
+	// В версии 1 страницы карты хранились целыми ссылками,
+	// их разбирает cursorLink при загрузке
+	if save.Version == 1 {
+		save.Version = 2
+	}
+
+	return save, nil
+}
+
+// writeFileAtomic записывает файл через временный,
+// чтобы при падении не остался наполовину записанный файл
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/CodeHunt7/go-pokedex/internal/pokeapi"
+)
+
+func TestSaveLoadGame(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "save.json")
+	caughtAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	cfg := &Config{
+		pokeapiClient: pokeapi.NewClient("", nil, nil),
+		Next:          "https://pokeapi.co/api/v2/location-area/?offset=40&limit=20",
+		Previous:      "https://pokeapi.co/api/v2/location-area/?offset=0&limit=20",
+		Pokedex: map[string]CaughtPokemon{
+			"pikachu": {
+				Pokemon:  pokeapi.PokemonResponse{Name: "pikachu", Height: 4},
+				CaughtAt: caughtAt,
+			},
+		},
+	}
+	if err := saveGame(cfg, path); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	loaded := &Config{pokeapiClient: pokeapi.NewClient("", nil, nil)}
+	found, err := loadGame(loaded, path)
+	if err != nil || !found {
+		t.Fatalf("expected to load save, found=%v err=%v", found, err)
+	}
+	if loaded.Next != cfg.Next || loaded.Previous != cfg.Previous {
+		t.Errorf("unexpected cursor: %q %q", loaded.Next, loaded.Previous)
+	}
+
+	// С другим адресом API карта листается с той же страницы по новому адресу
+	mirror := &Config{pokeapiClient: pokeapi.NewClient("http://localhost:8080/api/v2/", nil, nil)}
+	if _, err := loadGame(mirror, path); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if mirror.Next != "http://localhost:8080/api/v2/location-area/?offset=40&limit=20" {
+		t.Errorf("expected cursor on the new base, got %q", mirror.Next)
+	}
+	pikachu, ok := loaded.Pokedex["pikachu"]
+	if !ok || pikachu.Pokemon.Height != 4 || !pikachu.CaughtAt.Equal(caughtAt) {
+		t.Errorf("unexpected pokedex: %+v", loaded.Pokedex)
+	}
+}
+
+func TestLoadGameVersions(t *testing.T) {
+	cases := []struct {
+		data     string
+		expected string // ссылка на следующую страницу после загрузки
+		wantErr  bool
+	}{
+		{
+			data: `{"version": 1, "pokedex": {}}`,
+		},
+		{
+			data:     `{"version": 1, "pokedex": {}, "next": "https://pokeapi.co/api/v2/location-area/?offset=20&limit=20"}`,
+			expected: "https://pokeapi.co/api/v2/location-area/?offset=20&limit=20",
+		},
+		{
+			data: `{"version": 1, "pokedex": {}, "next": "https://mirror.example.com/location-area/?offset=20&limit=20"}`,
+		},
+		{
+			data:     `{"version": 2, "pokedex": {}, "next": "location-area/?offset=20&limit=20"}`,
+			expected: "https://pokeapi.co/api/v2/location-area/?offset=20&limit=20",
+		},
+		{
+			data:    `{"version": 99, "pokedex": {}}`,
+			wantErr: true,
+		},
+		{
+			data:    `{"pokedex": {}}`,
+			wantErr: true,
+		},
+	}
+
+	for _, c := range cases {
+		path := filepath.Join(t.TempDir(), "save.json")
+		if err := os.WriteFile(path, []byte(c.data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		cfg := &Config{pokeapiClient: pokeapi.NewClient("", nil, nil)}
+		_, err := loadGame(cfg, path)
+		if (err != nil) != c.wantErr {
+			t.Errorf("loadGame(%s) error = %v; want error %v", c.data, err, c.wantErr)
+		}
+		if cfg.Next != c.expected {
+			t.Errorf("loadGame(%s): expected next page %q, got %q", c.data, c.expected, cfg.Next)
+		}
+	}
+}
+
+func TestSaveLoadKeepsPathCase(t *testing.T) {
+	// t.TempDir содержит имя теста с заглавными буквами
+	path := filepath.Join(t.TempDir(), "Backup.json")
+
+	cfg, _, errOut := newTestConfig(t, nil, "")
+	cfg.Pokedex["pikachu"] = CaughtPokemon{}
+	if err := cfg.registry.dispatch(context.Background(), cfg, "SAVE "+path); err != nil {
+		t.Fatalf("save: %v, %s", err, errOut)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("expected save file at %s: %v", path, err)
+	}
+
+	loaded, _, errOut := newTestConfig(t, nil, "")
+	if err := loaded.registry.dispatch(context.Background(), loaded, "load "+path); err != nil {
+		t.Fatalf("load: %v, %s", err, errOut)
+	}
+	if _, ok := loaded.Pokedex["pikachu"]; !ok {
+		t.Errorf("expected pikachu after load, got %v", loaded.Pokedex)
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/CodeHunt7/go-pokedex/internal/pokeapi"
+)
+
+// Переменная окружения с адресом PokeAPI
+const apiBaseEnv = "POKEDEX_API_BASE"
+
+// Структура для пользовательского конфиг-файла
+type UserSettings struct {
+	APIBase string `json:"api_base,omitempty"`
+	Profile string `json:"profile,omitempty"` // последний активный профиль
+
+	Aliases map[string]string   `json:"aliases,omitempty"` // псевдоним -> команда с аргументами
+	Macros  map[string][]string `json:"macros,omitempty"`  // имя -> команды с $1..$9 и $@
+}
+
+// defaultSettingsPath возвращает путь к конфиг-файлу по умолчанию
+func defaultSettingsPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "pokedex", "config.json")
+}
+
+// loadSettings читает конфиг-файл, отсутствие файла не считается ошибкой
+func loadSettings(path string) (UserSettings, error) {
+	var settings UserSettings
+	if path == "" {
+		return settings, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return settings, nil
+	}
+	if err != nil {
+		return settings, err
+	}
+
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return settings, fmt.Errorf("parse %s: %w", path, err)
+	}
+
+	return settings, nil
+}
+
+// saveSettings записывает конфиг-файл
+func saveSettings(path string, settings UserSettings) error {
+	data, err := json.MarshalIndent(settings, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data)
+}
+
+// resolveAPIBase выбирает адрес API: флаг, затем переменная окружения,
+// затем конфиг-файл и в конце адрес по умолчанию
+func resolveAPIBase(flagValue string, settings UserSettings) string {
+	if flagValue != "" {
+		return flagValue
+	}
+	if env := os.Getenv(apiBaseEnv); env != "" {
+		return env
+	}
+	if settings.APIBase != "" {
+		return settings.APIBase
+	}
+	return pokeapi.DefaultBaseURL
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/CodeHunt7/go-pokedex/internal/pokeapi"
+)
+
+func TestResolveAPIBase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"api_base": "http://file.local/"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	settings, err := loadSettings(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cases := []struct {
+		flag     string
+		env      string
+		settings UserSettings
+		expected string
+	}{
+		{
+			flag:     "http://flag.local/",
+			env:      "http://env.local/",
+			settings: settings,
+			expected: "http://flag.local/",
+		},
+		{
+			env:      "http://env.local/",
+			settings: settings,
+			expected: "http://env.local/",
+		},
+		{
+			settings: settings,
+			expected: "http://file.local/",
+		},
+		{
+			expected: pokeapi.DefaultBaseURL,
+		},
+	}
+
+	for _, c := range cases {
+		t.Setenv(apiBaseEnv, c.env)
+		actual := resolveAPIBase(c.flag, c.settings)
+		if actual != c.expected {
+			t.Errorf("resolveAPIBase(%q) with env %q = %q; want %q", c.flag, c.env, actual, c.expected)
+		}
+	}
+}
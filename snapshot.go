@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/CodeHunt7/go-pokedex/internal/pokeapi"
+)
+
+// Подкоманды команды snapshot
+const snapshotUsage = "usage: snapshot build"
+
+func commandSnapshot(ctx context.Context, cfg *Config, parameters []string) error {
+	if parameters[0] != "build" {
+		return errors.New(snapshotUsage)
+	}
+	if cfg.offline {
+		return errors.New("snapshot build needs network access, restart the Pokedex without --offline")
+	}
+
+	// Путь берется из --snapshot: ввод команд приводится к нижнему регистру
+	path := cfg.snapshotPath
+	if path == "" {
+		return errors.New("no snapshot path, pass one with --snapshot")
+	}
+
+	// Прогресс пишем в поток ошибок, чтобы не мешать выводу в JSON
+	errOut := cfg.registry.errOut
+	manifest, err := cfg.pokeapiClient.BuildSnapshot(ctx, path, func(resource string, done, total int) {
+		fmt.Fprintf(errOut, "\rDownloading %s: %d/%d", resource, done, total)
+		if done == total {
+			fmt.Fprintln(errOut)
+		}
+	})
+	if err != nil {
+		fmt.Fprintln(errOut)
+		return err
+	}
+
+	size := 0
+	if info, err := os.Stat(path); err == nil {
+		size = int(info.Size())
+	}
+	return render(cfg, messageView{fmt.Sprintf("Saved %d responses to %s (%s), use it with --offline", manifest.Entries, path, formatBytes(size))})
+}
+
+// openSnapshot открывает снимок для режима без сети
+func openSnapshot(path string) (*pokeapi.Snapshot, error) {
+	if path == "" {
+		return nil, errors.New("no snapshot path, pass one with --snapshot")
+	}
+	snapshot, err := pokeapi.OpenSnapshot(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("no snapshot at %s, build one with 'snapshot build' while online", path)
+	}
+	return snapshot, err
+}
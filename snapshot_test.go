@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/CodeHunt7/go-pokedex/internal/pokeapi"
+)
+
+func TestSnapshotOffline(t *testing.T) {
+	server := newTestServer(t)
+	path := filepath.Join(t.TempDir(), "snapshot.zip")
+
+	// Снимок снимается, пока есть сеть
+	cfg, out, errOut := newTestConfig(t, server, "")
+	cfg.snapshotPath = path
+	if err := cfg.registry.dispatch(context.Background(), cfg, "snapshot build"); err != nil {
+		t.Fatalf("snapshot build: %v", err)
+	}
+	if !strings.Contains(out.String(), "Saved") || !strings.Contains(errOut.String(), "Downloading location-area: 2/2") {
+		t.Errorf("unexpected snapshot build output %q, %q", out.String(), errOut.String())
+	}
+	server.Close()
+
+	snapshot, err := openSnapshot(path)
+	if err != nil {
+		t.Fatalf("open snapshot: %v", err)
+	}
+	t.Cleanup(func() { snapshot.Close() })
+
+	cases := []struct {
+		input    string
+		expected string
+		errOut   string
+	}{
+		{input: "map", expected: "canalave-city-area"},
+		{input: "explore canalave-city-area", expected: "tentacool"},
+		{input: "explore eterna-city-areaa", errOut: `Did you mean "eterna-city-area"?`},
+		{input: "catch pikachu", errOut: `Error executing "catch" command: not available offline, the snapshot does not have it`},
+		{input: "snapshot build", errOut: "needs network access"},
+	}
+
+	for _, c := range cases {
+		cfg, out, errOut := newTestConfig(t, nil, "")
+		cfg.pokeapiClient = pokeapi.NewClient(snapshot.Manifest.BaseURL, &http.Client{Transport: snapshot}, cfg.pokeCache)
+		cfg.offline = true
+
+		cfg.registry.dispatch(context.Background(), cfg, c.input)
+		if !strings.Contains(out.String(), c.expected) {
+			t.Errorf("%q: expected %q in output, got %q", c.input, c.expected, out.String())
+		}
+		if !strings.Contains(errOut.String(), c.errOut) {
+			t.Errorf("%q: expected %q in errors, got %q", c.input, c.errOut, errOut.String())
+		}
+	}
+
+	if _, err := openSnapshot(filepath.Join(t.TempDir(), "missing.zip")); err == nil || !strings.Contains(err.Error(), "snapshot build") {
+		t.Errorf("expected a hint to build a snapshot, got %v", err)
+	}
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Сколько подсказок "did you mean" показывать
+const maxSuggestions = 3
+
+// suggest возвращает до maxSuggestions имен, ближайших к word.
+// Подходят имена на расстоянии не больше трети длины слова
+// и имена, которые начинаются с word
+func suggest(word string, names []string) []string {
+	limit := max(1, (len([]rune(word))+2)/3)
+
+	type scored struct {
+		name     string
+		distance int
+	}
+	var matches []scored
+	seen := make(map[string]bool)
+	for _, name := range names {
+		if seen[name] || name == word {
+			continue
+		}
+		seen[name] = true
+
+		distance := editDistance(word, name)
+		if distance > limit && strings.HasPrefix(name, word) {
+			distance = limit
+		}
+		if distance <= limit {
+			matches = append(matches, scored{name, distance})
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].distance != matches[j].distance {
+			return matches[i].distance < matches[j].distance
+		}
+		return matches[i].name < matches[j].name
+	})
+
+	suggestions := []string{}
+	for _, match := range matches[:min(len(matches), maxSuggestions)] {
+		suggestions = append(suggestions, match.name)
+	}
+	return suggestions
+}
+
+// editDistance считает расстояние Дамерау-Левенштейна:
+// вставка, удаление, замена и перестановка соседних символов стоят 1
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	d := make([][]int, len(ra)+1)
+	for i := range d {
+		d[i] = make([]int, len(rb)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(ra)][len(rb)]
+}
+
+// didYouMean дописывает к сообщению подсказки, если они есть
+func didYouMean(message string, suggestions []string) string {
+	if len(suggestions) == 0 {
+		return message
+	}
+
+	quoted := make([]string, 0, len(suggestions))
+	for _, suggestion := range suggestions {
+		quoted = append(quoted, strconv.Quote(suggestion))
+	}
+	return fmt.Sprintf("%s. Did you mean %s?", message, strings.Join(quoted, " or "))
+}
+
+// knownName проверяет имя по полному списку имен из API.
+// Без списка и для числовых id проверять не по чему, и имя считается известным
+func knownName(name string, names []string) bool {
+	if len(names) == 0 {
+		return true
+	}
+	if _, err := strconv.Atoi(name); err == nil {
+		return true
+	}
+	return slices.Contains(names, name)
+}
+
+// errUnknownName - введенного имени нет среди известных покемонов или локаций
+var errUnknownName = errors.New("unknown name")
+
+// unknownNameError хранит сообщение о неизвестном имени вместе с подсказками
+type unknownNameError struct {
+	message string
+}
+
+func (e *unknownNameError) Error() string {
+	return e.message
+}
+
+func (e *unknownNameError) Is(target error) bool {
+	return target == errUnknownName
+}
+
+// unknownPokemonError сообщает о неизвестном покемоне с подсказками
+func unknownPokemonError(name string, names []string) error {
+	return &unknownNameError{didYouMean(fmt.Sprintf("unknown pokemon %q", name), suggest(name, names))}
+}
+
+// unknownAreaError сообщает о неизвестной локации с подсказками
+func unknownAreaError(name string, names []string) error {
+	return &unknownNameError{didYouMean(fmt.Sprintf("unknown location area %q", name), suggest(name, names))}
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestEditDistance(t *testing.T) {
+	cases := []struct {
+		a, b     string
+		expected int
+	}{
+		{a: "help", b: "help", expected: 0},
+		{a: "hlep", b: "help", expected: 1},
+		{a: "pikchu", b: "pikachu", expected: 1},
+		{a: "bulbasuar", b: "bulbasaur", expected: 1},
+		{a: "map", b: "mapb", expected: 1},
+		{a: "", b: "abc", expected: 3},
+		{a: "flabébé", b: "flabebe", expected: 2},
+	}
+
+	for _, c := range cases {
+		if actual := editDistance(c.a, c.b); actual != c.expected {
+			t.Errorf("editDistance(%q, %q): expected %d, got %d", c.a, c.b, c.expected, actual)
+		}
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	names := []string{"pikachu", "pichu", "raichu", "canalave-city-area", "eterna-city-area", "mr-mime"}
+
+	cases := []struct {
+		word     string
+		expected []string
+	}{
+		{word: "pikchu", expected: []string{"pichu", "pikachu"}},
+		{word: "canalave", expected: []string{"canalave-city-area"}},
+		{word: "mrmime", expected: []string{"mr-mime"}},
+		{word: "zzz", expected: []string{}},
+	}
+
+	for _, c := range cases {
+		actual := suggest(c.word, names)
+		if strings.Join(actual, ",") != strings.Join(c.expected, ",") {
+			t.Errorf("%q: expected %v, got %v", c.word, c.expected, actual)
+		}
+	}
+}
+
+func TestDispatchUnknownSuggests(t *testing.T) {
+	cfg, out, errOut := newTestConfig(t, nil, "")
+
+	if err := cfg.registry.dispatch(context.Background(), cfg, "hlep"); err == nil {
+		t.Errorf("expected error for unknown command")
+	}
+	// Подсказка идет в поток ошибок, чтобы не ломать вывод в JSON
+	if !strings.Contains(errOut.String(), `Did you mean "help"?`) || out.Len() != 0 {
+		t.Errorf("expected suggestion on stderr only, got %q and %q", out.String(), errOut.String())
+	}
+}
+
+func TestTypoCaughtBeforeRequest(t *testing.T) {
+	// Запоминаем, какие ресурсы запрашивались
+	var mu sync.Mutex
+	requested := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requested = append(requested, r.URL.Path)
+		mu.Unlock()
+
+		switch r.URL.Path {
+		case "/pokemon/":
+			fmt.Fprint(w, `{"results": [{"name": "pikachu"}, {"name": "pidgey"}]}`)
+		case "/location-area/":
+			fmt.Fprint(w, `{"results": [{"name": "canalave-city-area"}]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	// Неизвестные имена - ошибки команды, а не обычный вывод
+	cases := []struct {
+		input    string
+		expected string
+		isError  bool
+	}{
+		{input: "catch pikchu", expected: `Error executing "catch" command: unknown pokemon "pikchu". Did you mean "pikachu"?`, isError: true},
+		{input: "explore canalave-city", expected: `Error executing "explore" command: unknown location area "canalave-city". Did you mean "canalave-city-area"?`, isError: true},
+		{input: "inspect pikchu", expected: `you have not caught that pokemon. Did you mean "pikachu"?`},
+	}
+
+	for _, c := range cases {
+		cfg, out, errOut := newTestConfig(t, server, "")
+		cfg.Pokedex["pikachu"] = CaughtPokemon{}
+
+		err := cfg.registry.dispatch(context.Background(), cfg, c.input)
+		actual := out.String()
+		if c.isError {
+			actual = errOut.String()
+			if !errors.Is(err, errUnknownName) {
+				t.Errorf("%q: expected errUnknownName, got %v", c.input, err)
+			}
+		} else if err != nil {
+			t.Errorf("%q: unexpected error: %v", c.input, err)
+		}
+		if !strings.Contains(actual, c.expected) {
+			t.Errorf("%q: expected %q, got %q", c.input, c.expected, actual)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	for _, path := range requested {
+		if path != "/pokemon/" && path != "/location-area/" {
+			t.Errorf("expected only name index requests, got %s", path)
+		}
+	}
+}